@@ -6,8 +6,88 @@ SPDX-License-Identifier: Apache-2.0
 
 package event
 
+import "encoding/json"
+
 type Event struct {
 	Address string
 	Data    string
 	Topics  []string
 }
+
+// CallEvent is a call made by a contract during a transaction, which is an
+// internal transaction of the transaction. Depth is 1 for the calls made by
+// the contract called by the transaction, and increases by one for each
+// nested call. The addresses, input and output are hex encoded, the input and
+// output are only recorded when call data is recorded.
+//
+// CallType is one of call, callcode, delegatecall and staticcall for the
+// calls of the corresponding opcodes and create for the contracts created with
+// CREATE and CREATE2, whose input is their init code and output their runtime
+// code. The calls of the precompiles of evmcc are not recorded.
+type CallEvent struct {
+	CallType string
+	Caller   string
+	Callee   string
+	Input    string
+	Output   string
+	Value    uint64
+	Depth    uint64
+	Error    string `json:",omitempty"`
+}
+
+// Payload is the payload of the Fabric event of an EVM transaction: the logs
+// emitted by its contracts and the calls they made, in the order the calls
+// were made. Unless it is Structured, the payload is only the list of logs,
+// as it was before calls were recorded.
+type Payload struct {
+	Logs  []Event
+	Calls []CallEvent
+	// Structured payloads are encoded as an object of logs and calls
+	Structured bool `json:"-"`
+}
+
+// MarshalJSON encodes the payload as the list of its logs when it is not
+// structured. Missing logs and calls are encoded as empty lists.
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if p.Logs == nil {
+		p.Logs = []Event{}
+	}
+	if !p.Structured {
+		return json.Marshal(p.Logs)
+	}
+
+	if p.Calls == nil {
+		p.Calls = []CallEvent{}
+	}
+	type payload Payload
+	return json.Marshal(payload(p))
+}
+
+// UnmarshalJSON decodes a payload, or a list of logs.
+func (p *Payload) UnmarshalJSON(data []byte) error {
+	var logs []Event
+	if err := json.Unmarshal(data, &logs); err == nil {
+		*p = Payload{Logs: logs}
+		return nil
+	}
+
+	type payload Payload
+	if err := json.Unmarshal(data, (*payload)(p)); err != nil {
+		return err
+	}
+	p.Structured = true
+	return nil
+}
+
+// AuditEventPrefix prefixes the names of the events emitted by the
+// administrative functions of evmcc. Their payload is an AuditEvent rather
+// than the logs of the EVM.
+const AuditEventPrefix = "evmcc:audit:"
+
+// AuditEvent records an administrative operation on a contract.
+type AuditEvent struct {
+	Operation string
+	Address   string
+	Admin     string
+	CodeHash  string `json:",omitempty"`
+}
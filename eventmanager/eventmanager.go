@@ -7,21 +7,69 @@ SPDX-License-Identifier: Apache-2.0
 package eventmanager
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/hyperledger/fabric-chaincode-evm/event"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 
 	"github.com/hyperledger/burrow/execution/errors"
-	"github.com/hyperledger/burrow/execution/evm"
 	"github.com/hyperledger/burrow/execution/exec"
 )
 
 type EventManager struct {
 	Stub       shim.ChaincodeStubInterface
 	EventCache []event.Event
+	// CallCache holds the calls made by contracts, in the order they returned
+	CallCache []event.CallEvent
+	// NameTemplate is the name of the Fabric event, in which the placeholders
+	// of the NamePlaceholders are replaced. The name passed to Flush is used
+	// when it is empty.
+	NameTemplate string
+	// StructuredPayload sets the payload as an object of logs and calls rather
+	// than the list of logs.
+	StructuredPayload bool
+	// RecordCalls records the calls made by contracts, which are only set in
+	// structured payloads. Their input and output are only recorded with
+	// RecordCallData, as they may hold private data read by precompiles or
+	// inputs passed in the transient map.
+	RecordCalls    bool
+	RecordCallData bool
+}
+
+// The placeholders of the name template of the Fabric event.
+const (
+	// NamePlaceholder is replaced by the name passed to Flush.
+	NamePlaceholder = "{name}"
+	// AddressPlaceholder is replaced by the address of the contract which
+	// emitted the first log.
+	AddressPlaceholder = "{address}"
+	// Topic0Placeholder is replaced by the first topic of the first log,
+	// which is the hash of the signature of a Solidity event.
+	Topic0Placeholder = "{topic0}"
+)
+
+var placeholderRegexp = regexp.MustCompile(`\{[^{}]*\}`)
+
+// ValidateNameTemplate returns an error when a name template of the Fabric
+// event is empty or holds unknown placeholders.
+func ValidateNameTemplate(template string) error {
+	if template == "" {
+		return fmt.Errorf("event name template must not be empty")
+	}
+
+	for _, placeholder := range placeholderRegexp.FindAllString(template, -1) {
+		switch placeholder {
+		case NamePlaceholder, AddressPlaceholder, Topic0Placeholder:
+		default:
+			return fmt.Errorf("unknown placeholder %s", placeholder)
+		}
+	}
+	return nil
 }
 
 var _ evm.EventSink = &EventManager{}
@@ -31,27 +79,123 @@ type EventSink interface {
 	Log(log *exec.LogEvent) error
 }
 
-// Flush will marshal all collected events from the transaction
+// callTypes maps the types of the calls of the EVM to the call types of the
+// call events.
+var callTypes = map[exec.CallType]string{
+	exec.CallTypeCall:     "call",
+	exec.CallTypeCode:     "callcode",
+	exec.CallTypeDelegate: "delegatecall",
+	exec.CallTypeStatic:   "staticcall",
+	evm.CallTypeCreate:    "create",
+}
+
+// Flush will marshal all collected events and calls from the transaction
 // and set as a singular Fabric event
 //
-// eventName is for fabric, typically the evm 8byte function hash
+// eventName is for fabric, typically the evm 8byte function hash, and is
+// replaced by the NameTemplate when one is set
 func (evmgr *EventManager) Flush(eventName string) error {
-	if len(evmgr.EventCache) == 0 {
+	if len(evmgr.EventCache) == 0 && (!evmgr.StructuredPayload || len(evmgr.CallCache) == 0) {
 		return nil
 	}
-	payload, err := json.Marshal(evmgr.EventCache)
+
+	payload, err := json.Marshal(event.Payload{
+		Logs:       evmgr.EventCache,
+		Calls:      callOrder(evmgr.CallCache),
+		Structured: evmgr.StructuredPayload,
+	})
 	if err != nil {
 		return fmt.Errorf("Failed to marshal event messages: %s", err)
 	}
-	return evmgr.Stub.SetEvent(eventName, payload)
+	return evmgr.Stub.SetEvent(evmgr.eventName(eventName), payload)
+}
+
+// eventName returns the name of the Fabric event, which is the NameTemplate
+// with its placeholders replaced, or name when there is no template. The
+// address and topic placeholders are replaced by empty strings when no log
+// was emitted, or the first log has no topics.
+func (evmgr *EventManager) eventName(name string) string {
+	if evmgr.NameTemplate == "" {
+		return name
+	}
+
+	var address, topic0 string
+	if len(evmgr.EventCache) > 0 {
+		address = evmgr.EventCache[0].Address
+		if len(evmgr.EventCache[0].Topics) > 0 {
+			topic0 = evmgr.EventCache[0].Topics[0]
+		}
+	}
+
+	return strings.NewReplacer(
+		NamePlaceholder, name,
+		AddressPlaceholder, address,
+		Topic0Placeholder, topic0,
+	).Replace(evmgr.NameTemplate)
 }
 
-// Call for right now is a noop
-// need to figure out what it means (burrow or evm)
+// Call will take the given call made by a contract, along with its exception
+// when it failed, convert it to a call event and add it to the event
+// manager's CallCache when calls are recorded. The call of the transaction
+// itself, at stack depth 0, is not a call made by a contract and the calls of
+// precompiles, whose input and output may be private, are ignored.
 func (evmgr *EventManager) Call(call *exec.CallEvent, exception *errors.Exception) error {
+	if !evmgr.RecordCalls || call.StackDepth == 0 || call.CallData == nil || call.CallType == exec.CallTypeSNative {
+		return nil
+	}
+
+	callType, ok := callTypes[call.CallType]
+	if !ok {
+		return fmt.Errorf("Unknown call type %s", call.CallType)
+	}
+
+	e := event.CallEvent{
+		CallType: callType,
+		Caller:   strings.ToLower(call.CallData.Caller.String()),
+		Callee:   strings.ToLower(call.CallData.Callee.String()),
+		Value:    call.CallData.Value,
+		Depth:    call.StackDepth,
+	}
+	if evmgr.RecordCallData {
+		e.Input = hex.EncodeToString(call.CallData.Data)
+		e.Output = hex.EncodeToString(call.Return)
+	}
+	if exception != nil {
+		e.Error = exception.Error()
+	}
+
+	evmgr.CallCache = append(evmgr.CallCache, e)
+
 	return nil
 }
 
+// callOrder reorders calls, which the EVM reports once they returned after the
+// calls they made, in the order they were made.
+func callOrder(calls []event.CallEvent) []event.CallEvent {
+	// subtrees holds the calls whose caller has not returned yet, each
+	// followed by the calls it made
+	var subtrees [][]event.CallEvent
+	for _, call := range calls {
+		// The deeper subtrees at the end are the calls made by call
+		i := len(subtrees)
+		for i > 0 && subtrees[i-1][0].Depth > call.Depth {
+			i--
+		}
+
+		subtree := []event.CallEvent{call}
+		for _, child := range subtrees[i:] {
+			subtree = append(subtree, child...)
+		}
+		subtrees = append(subtrees[:i], subtree)
+	}
+
+	var ordered []event.CallEvent
+	for _, subtree := range subtrees {
+		ordered = append(ordered, subtree...)
+	}
+	return ordered
+}
+
 // Log will take the given log message convert to a event type and
 // append to the event manager's EventCache
 func (evmgr *EventManager) Log(log *exec.LogEvent) error {
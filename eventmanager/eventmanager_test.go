@@ -9,16 +9,18 @@ package eventmanager_test
 import (
 	"encoding/hex"
 	"encoding/json"
-	"errors"
+	goerrors "errors"
 	"strings"
 
 	"github.com/hyperledger/burrow/binary"
 	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/errors"
 	"github.com/hyperledger/burrow/execution/exec"
 
 	"github.com/hyperledger/fabric-chaincode-evm/event"
 	"github.com/hyperledger/fabric-chaincode-evm/eventmanager"
 	mocks "github.com/hyperledger/fabric-chaincode-evm/mocks/evmcc"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -77,12 +79,79 @@ var _ = Describe("Event", func() {
 	})
 
 	Describe("Call", func() {
-		It("is a noop", func() {
-			originalLength := len(eventManager.EventCache)
+		var (
+			callee crypto.Address
+			call   *exec.CallEvent
+		)
+
+		BeforeEach(func() {
+			var err error
+			callee, err = crypto.AddressFromBytes([]byte("0000000000000calleez"))
+			Expect(err).ToNot(HaveOccurred())
+
+			call = &exec.CallEvent{
+				CallType:   exec.CallTypeStatic,
+				CallData:   &exec.CallData{Caller: addr, Callee: callee, Data: []byte("input"), Value: 10},
+				StackDepth: 1,
+				Return:     []byte("output"),
+			}
+			eventManager.RecordCalls = true
+			eventManager.RecordCallData = true
+		})
+
+		It("appends the call into the callCache", func() {
+			err := eventManager.Call(call, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(eventManager.CallCache).To(Equal([]event.CallEvent{{
+				CallType: "staticcall",
+				Caller:   strings.ToLower(addr.String()),
+				Callee:   strings.ToLower(callee.String()),
+				Input:    hex.EncodeToString([]byte("input")),
+				Output:   hex.EncodeToString([]byte("output")),
+				Value:    10,
+				Depth:    1,
+			}}))
+			Expect(eventManager.EventCache).To(BeEmpty())
+		})
+
+		It("records the exception of a failed call", func() {
+			call.CallType = evm.CallTypeCreate
+			err := eventManager.Call(call, errors.NewException(errors.ErrorCodeExecutionReverted, "reverted"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(eventManager.CallCache).To(HaveLen(1))
+			Expect(eventManager.CallCache[0].CallType).To(Equal("create"))
+			Expect(eventManager.CallCache[0].Error).To(ContainSubstring("reverted"))
+		})
+
+		It("leaves out the input and output unless call data is recorded", func() {
+			eventManager.RecordCallData = false
+			err := eventManager.Call(call, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(eventManager.CallCache).To(HaveLen(1))
+			Expect(eventManager.CallCache[0].Input).To(BeEmpty())
+			Expect(eventManager.CallCache[0].Output).To(BeEmpty())
+			Expect(eventManager.CallCache[0].Value).To(Equal(uint64(10)))
+		})
+
+		It("ignores the call of the transaction", func() {
+			call.StackDepth = 0
+			err := eventManager.Call(call, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(eventManager.CallCache).To(BeEmpty())
+		})
+
+		It("ignores the calls of precompiles", func() {
+			call.CallType = exec.CallTypeSNative
+			err := eventManager.Call(call, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(eventManager.CallCache).To(BeEmpty())
+		})
 
-			err := eventManager.Call(&exec.CallEvent{}, nil)
+		It("ignores calls unless calls are recorded", func() {
+			eventManager.RecordCalls = false
+			err := eventManager.Call(call, nil)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(eventManager.EventCache).To(HaveLen(originalLength))
+			Expect(eventManager.CallCache).To(BeEmpty())
 		})
 	})
 
@@ -142,9 +211,137 @@ var _ = Describe("Event", func() {
 			})
 		})
 
+		Context("when contracts made calls", func() {
+			call := func(depth uint64, input string) *exec.CallEvent {
+				return &exec.CallEvent{CallData: &exec.CallData{Caller: addr, Callee: addr, Data: []byte(input)}, StackDepth: depth}
+			}
+
+			BeforeEach(func() {
+				eventManager.StructuredPayload = true
+				eventManager.RecordCalls = true
+				eventManager.RecordCallData = true
+			})
+
+			It("sets a new event with a payload of the logs and the calls in the order they were made", func() {
+				err := eventManager.Log(message1)
+				Expect(err).ToNot(HaveOccurred())
+
+				// The EVM reports calls once they returned: a made b, which
+				// made c, then a made d, which made e
+				for _, c := range []*exec.CallEvent{call(3, "c"), call(2, "b"), call(3, "e"), call(2, "d"), call(1, "a")} {
+					Expect(eventManager.Call(c, nil)).To(Succeed())
+				}
+				Expect(eventManager.Call(call(1, "f"), nil)).To(Succeed())
+
+				err = eventManager.Flush("Chaincode event")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockStub.SetEventCallCount()).To(Equal(1))
+				_, setEventPayload := mockStub.SetEventArgsForCall(0)
+
+				var payload event.Payload
+				Expect(json.Unmarshal(setEventPayload, &payload)).To(Succeed())
+				Expect(payload.Logs).To(Equal([]event.Event{{Address: strings.ToLower(addr.String())}}))
+
+				var inputs []string
+				var depths []uint64
+				for _, c := range payload.Calls {
+					input, err := hex.DecodeString(c.Input)
+					Expect(err).ToNot(HaveOccurred())
+					inputs = append(inputs, string(input))
+					depths = append(depths, c.Depth)
+				}
+				Expect(inputs).To(Equal([]string{"a", "b", "c", "d", "e", "f"}))
+				Expect(depths).To(Equal([]uint64{1, 2, 3, 2, 3, 1}))
+			})
+
+			It("sets a new event when no logs were emitted", func() {
+				Expect(eventManager.Call(call(1, "a"), nil)).To(Succeed())
+				err := eventManager.Flush("Chaincode event")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockStub.SetEventCallCount()).To(Equal(1))
+				_, setEventPayload := mockStub.SetEventArgsForCall(0)
+				Expect(setEventPayload).To(MatchJSON(`{"Logs":[],"Calls":[{"CallType":"call","Caller":"` + strings.ToLower(addr.String()) +
+					`","Callee":"` + strings.ToLower(addr.String()) + `","Input":"61","Output":"","Value":0,"Depth":1}]}`))
+			})
+
+			It("sets the list of logs without the calls when the payload is not structured", func() {
+				eventManager.StructuredPayload = false
+				Expect(eventManager.Log(message1)).To(Succeed())
+				Expect(eventManager.Call(call(1, "a"), nil)).To(Succeed())
+				err := eventManager.Flush("Chaincode event")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockStub.SetEventCallCount()).To(Equal(1))
+				_, setEventPayload := mockStub.SetEventArgsForCall(0)
+				Expect(setEventPayload).To(MatchJSON(`[{"Address":"` + strings.ToLower(addr.String()) + `","Data":"","Topics":null}]`))
+			})
+
+			It("does not set an event without logs when the payload is not structured", func() {
+				eventManager.StructuredPayload = false
+				Expect(eventManager.Call(call(1, "a"), nil)).To(Succeed())
+				err := eventManager.Flush("Chaincode event")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mockStub.SetEventCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a name template is set", func() {
+			BeforeEach(func() {
+				eventManager.NameTemplate = "evm:{address}:{topic0}:{name}"
+			})
+
+			It("names the event after the address and the first topic of the first log", func() {
+				message1.Topics = topics
+				Expect(eventManager.Log(message1)).To(Succeed())
+				Expect(eventManager.Log(message2)).To(Succeed())
+				err := eventManager.Flush("12345678")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockStub.SetEventCallCount()).To(Equal(1))
+				setEventName, _ := mockStub.SetEventArgsForCall(0)
+				Expect(setEventName).To(Equal("evm:" + strings.ToLower(addr.String()) + ":" + expectedTopics[0] + ":12345678"))
+			})
+
+			It("leaves out the address and the topic when no log was emitted", func() {
+				eventManager.StructuredPayload = true
+				eventManager.RecordCalls = true
+				call := &exec.CallEvent{CallData: &exec.CallData{Caller: addr, Callee: addr}, StackDepth: 1}
+				Expect(eventManager.Call(call, nil)).To(Succeed())
+				err := eventManager.Flush("12345678")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockStub.SetEventCallCount()).To(Equal(1))
+				setEventName, _ := mockStub.SetEventArgsForCall(0)
+				Expect(setEventName).To(Equal("evm:::12345678"))
+			})
+		})
+
+		Context("when the payload is structured", func() {
+			BeforeEach(func() {
+				eventManager.StructuredPayload = true
+			})
+
+			It("sets the logs and the calls as an object when no contract made a call", func() {
+				Expect(eventManager.Log(message1)).To(Succeed())
+				err := eventManager.Flush("Chaincode event")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockStub.SetEventCallCount()).To(Equal(1))
+				_, setEventPayload := mockStub.SetEventArgsForCall(0)
+				Expect(setEventPayload).To(MatchJSON(`{"Logs":[{"Address":"` + strings.ToLower(addr.String()) + `","Data":"","Topics":null}],"Calls":[]}`))
+
+				var payload event.Payload
+				Expect(json.Unmarshal(setEventPayload, &payload)).To(Succeed())
+				Expect(payload.Structured).To(BeTrue())
+				Expect(payload.Logs).To(HaveLen(1))
+			})
+		})
+
 		Context("when the event name is invalid (empty string)", func() {
 			BeforeEach(func() {
-				mockStub.SetEventReturns(errors.New("error: nil event name"))
+				mockStub.SetEventReturns(goerrors.New("error: nil event name"))
 			})
 
 			It("returns an error", func() {
@@ -157,4 +354,20 @@ var _ = Describe("Event", func() {
 			})
 		})
 	})
+
+	Describe("ValidateNameTemplate", func() {
+		It("accepts templates of known placeholders", func() {
+			Expect(eventmanager.ValidateNameTemplate("evm:{address}:{topic0}")).To(Succeed())
+			Expect(eventmanager.ValidateNameTemplate("{name}")).To(Succeed())
+			Expect(eventmanager.ValidateNameTemplate("evm")).To(Succeed())
+		})
+
+		It("rejects empty templates", func() {
+			Expect(eventmanager.ValidateNameTemplate("")).To(MatchError("event name template must not be empty"))
+		})
+
+		It("rejects unknown placeholders", func() {
+			Expect(eventmanager.ValidateNameTemplate("evm:{contract}")).To(MatchError("unknown placeholder {contract}"))
+		})
+	})
 })
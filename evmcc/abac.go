@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// ABACPrecompileAddress is the address of the precompile through which
+// contracts check the identity which submitted the transaction. Its interface
+// is FabricABAC in solidity/FabricABAC.sol.
+var ABACPrecompileAddress = crypto.Address{18: 0xfa, 19: 0xb3}
+
+func init() {
+	registerPrecompile(ABACPrecompileAddress, "FabricABAC", map[string]precompileFunction{
+		"hasAttribute(string,string)": hasAttribute,
+		"getAttribute(string)":        getAttribute,
+		"callerMSPID()":               callerMSPID,
+		"callerOUs()":                 callerOUs,
+	})
+}
+
+// hasAttribute returns whether the certificate of the creator holds the
+// attribute with the given value.
+func hasAttribute(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	name, err := args.String(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attribute name: %s", err)
+	}
+
+	value, err := args.String(1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attribute value: %s", err)
+	}
+
+	attrs, err := creatorAttributes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := attrs[name]
+	return abiEncode(ok && v == value), nil
+}
+
+// getAttribute returns the value of an attribute of the creator and whether
+// its certificate holds the attribute.
+func getAttribute(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	name, err := args.String(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attribute name: %s", err)
+	}
+
+	attrs, err := creatorAttributes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := attrs[name]
+	return abiEncode(value, ok), nil
+}
+
+func callerMSPID(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	identity, err := getCreator(ctx.stub)
+	if err != nil {
+		return nil, err
+	}
+	return abiEncode(identity.mspID), nil
+}
+
+// callerOUs returns the organizational units of the certificate of the
+// creator.
+func callerOUs(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	identity, err := getCreator(ctx.stub)
+	if err != nil {
+		return nil, err
+	}
+	return abiEncode(identity.cert.Subject.OrganizationalUnit), nil
+}
+
+func creatorAttributes(ctx *nativeContext) (map[string]string, error) {
+	identity, err := getCreator(ctx.stub)
+	if err != nil {
+		return nil, err
+	}
+	return identity.attributes()
+}
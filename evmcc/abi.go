@@ -0,0 +1,200 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// The precompiles of evmcc take and return ABI encoded values. The abi package
+// of burrow cannot decode dynamic arrays such as bytes[], so evmcc encodes and
+// decodes the few types its precompiles use itself.
+//
+// https://solidity.readthedocs.io/en/latest/abi-spec.html
+
+// abiArgs are the ABI encoded arguments of a call, without its function
+// selector. The head of argument i is the i-th 32 bytes word, which holds the
+// value of static types and the offset of the value of dynamic types.
+type abiArgs []byte
+
+func (a abiArgs) word(i int) ([]byte, error) {
+	start := uint64(i) * binary.Word256Length
+	if start+binary.Word256Length > uint64(len(a)) {
+		return nil, fmt.Errorf("missing argument %d", i)
+	}
+	return a[start : start+binary.Word256Length], nil
+}
+
+// Uint64 decodes argument i as an unsigned integer which fits in 64 bits.
+func (a abiArgs) Uint64(i int) (uint64, error) {
+	word, err := a.word(i)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, b := range word[:24] {
+		if b != 0 {
+			return 0, fmt.Errorf("argument %d does not fit in 64 bits", i)
+		}
+	}
+	return binary.GetUint64BE(word[24:]), nil
+}
+
+// Word decodes argument i as a 32 bytes word, such as a uint256 or a bytes32.
+func (a abiArgs) Word(i int) (binary.Word256, error) {
+	word, err := a.word(i)
+	if err != nil {
+		return binary.Zero256, err
+	}
+	return binary.LeftPadWord256(word), nil
+}
+
+// Bytes decodes the dynamic argument i as bytes.
+func (a abiArgs) Bytes(i int) ([]byte, error) {
+	tail, err := a.tail(i)
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := tail.Uint64(0)
+	if err != nil {
+		return nil, fmt.Errorf("missing length of argument %d", i)
+	}
+
+	if length > uint64(len(tail)-binary.Word256Length) {
+		return nil, fmt.Errorf("argument %d is shorter than its length %d", i, length)
+	}
+	return tail[binary.Word256Length : binary.Word256Length+length], nil
+}
+
+// String decodes the dynamic argument i as a string.
+func (a abiArgs) String(i int) (string, error) {
+	value, err := a.Bytes(i)
+	return string(value), err
+}
+
+// BytesArray decodes the dynamic argument i as an array of bytes.
+func (a abiArgs) BytesArray(i int) ([][]byte, error) {
+	tail, err := a.tail(i)
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := tail.Uint64(0)
+	if err != nil {
+		return nil, fmt.Errorf("missing length of argument %d", i)
+	}
+
+	// The elements are encoded as the arguments of a call
+	elements := tail[binary.Word256Length:]
+	if length > uint64(len(elements)/binary.Word256Length) {
+		return nil, fmt.Errorf("argument %d is shorter than its length %d", i, length)
+	}
+
+	values := make([][]byte, length)
+	for j := range values {
+		if values[j], err = elements.Bytes(j); err != nil {
+			return nil, fmt.Errorf("element %d of argument %d: %s", j, i, err)
+		}
+	}
+	return values, nil
+}
+
+// StringArray decodes the dynamic argument i as an array of strings.
+func (a abiArgs) StringArray(i int) ([]string, error) {
+	values, err := a.BytesArray(i)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(values))
+	for j := range values {
+		strs[j] = string(values[j])
+	}
+	return strs, nil
+}
+
+// tail returns the encoding starting at the offset held by the head of the
+// dynamic argument i.
+func (a abiArgs) tail(i int) (abiArgs, error) {
+	offset, err := a.Uint64(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > uint64(len(a)) {
+		return nil, fmt.Errorf("offset of argument %d is out of range", i)
+	}
+	return a[offset:], nil
+}
+
+// abiEncode returns the ABI encoding of values, as the return values of a
+// function. The values can be bools, uint64s, int64s, addresses, words, bytes,
+// strings and arrays of bytes or strings.
+func abiEncode(values ...interface{}) []byte {
+	head := make([]byte, 0, len(values)*binary.Word256Length)
+	var tail []byte
+	for _, value := range values {
+		var word binary.Word256
+		switch v := value.(type) {
+		case bool:
+			if v {
+				word = binary.One256
+			}
+		case uint64:
+			word = binary.Uint64ToWord256(v)
+		case int64:
+			word = binary.Int64ToWord256(v)
+			if v < 0 {
+				// Sign extend the two's complement
+				for j := 0; j < 24; j++ {
+					word[j] = 0xff
+				}
+			}
+		case crypto.Address:
+			word = v.Word256()
+		case binary.Word256:
+			word = v
+		default:
+			// Dynamic values are appended to the tail and their head is their
+			// offset
+			word = binary.Uint64ToWord256(uint64(len(values)*binary.Word256Length + len(tail)))
+			tail = append(tail, abiEncodeDynamic(value)...)
+		}
+		head = append(head, word.Bytes()...)
+	}
+	return append(head, tail...)
+}
+
+func abiEncodeDynamic(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		length := binary.Uint64ToWord256(uint64(len(v)))
+		paddedLength := (len(v) + binary.Word256Length - 1) / binary.Word256Length * binary.Word256Length
+		return append(length.Bytes(), binary.RightPadBytes(v, paddedLength)...)
+	case string:
+		return abiEncodeDynamic([]byte(v))
+	case [][]byte:
+		elements := make([]interface{}, len(v))
+		for i := range v {
+			elements[i] = v[i]
+		}
+		length := binary.Uint64ToWord256(uint64(len(v)))
+		return append(length.Bytes(), abiEncode(elements...)...)
+	case []string:
+		elements := make([][]byte, len(v))
+		for i := range v {
+			elements[i] = []byte(v[i])
+		}
+		return abiEncodeDynamic(elements)
+	default:
+		panic(fmt.Sprintf("cannot ABI encode %T", value))
+	}
+}
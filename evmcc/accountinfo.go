@@ -0,0 +1,65 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/burrow/crypto/sha3"
+	"github.com/hyperledger/burrow/permission"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// AccountInfo describes the account stored under an address. Only the
+// address is set for accounts which do not exist.
+type AccountInfo struct {
+	Address     string   `json:"address"`
+	Exists      bool     `json:"exists"`
+	Balance     uint64   `json:"balance"`
+	Sequence    uint64   `json:"sequence"`
+	CodeSize    int      `json:"codeSize"`
+	CodeHash    string   `json:"codeHash,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// getAccount returns a JSON description of an account: its balance, sequence,
+// the size and keccak256 hash of its code and its base permission flags.
+func (evmcc *EvmChaincode) getAccount(stub shim.ChaincodeStubInterface, address []byte) pb.Response {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	acct, err := statemanager.NewStateManager(stub).GetAccount(addr)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to get account: %s", err))
+	}
+
+	info := AccountInfo{
+		Address:     strings.ToLower(addr.String()),
+		Permissions: []string{},
+	}
+	if acct != nil {
+		info.Exists = true
+		info.Balance = acct.Balance
+		info.Sequence = acct.Sequence
+		info.CodeSize = len(acct.Code)
+		info.CodeHash = hex.EncodeToString(sha3.Sha3(acct.Code))
+		info.Permissions = permission.BasePermissionsToStringList(acct.Permissions.Base)
+	}
+
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal account: %s", err))
+	}
+	return shim.Success(infoBytes)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// ACLKeyPrefix prefixes the world state keys holding the policy of the
+// identities allowed to call a contract. Everyone may call a contract which
+// has no ACL.
+const ACLKeyPrefix = "evmcc:acl:"
+
+// ACLKey returns the world state key of the ACL of a contract.
+func ACLKey(addr crypto.Address) string {
+	return ACLKeyPrefix + strings.ToLower(addr.String())
+}
+
+// checkCaller returns an error unless the transaction was submitted by an
+// identity allowed to call the contract.
+func checkCaller(stub shim.ChaincodeStubInterface, contractAddr crypto.Address) error {
+	policy, err := getPolicy(stub, ACLKey(contractAddr))
+	if err != nil {
+		return err
+	}
+
+	if policy.isEmpty() {
+		return nil
+	}
+
+	identity, err := getCreator(stub)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := policy.allows(identity)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return fmt.Errorf("identity of %s is not allowed to call contract %s", identity.mspID, strings.ToLower(contractAddr.String()))
+	}
+	return nil
+}
+
+// setACL replaces the ACL of a contract and returns it as JSON. Only members
+// of the admin MSP may change it, an empty list allows everyone to call the
+// contract.
+func (evmcc *EvmChaincode) setACL(stub shim.ChaincodeStubInterface, address, acl []byte) pb.Response {
+	if err := checkAdmin(stub); err != nil {
+		return shim.Error(fmt.Sprintf("unauthorized: %s", err))
+	}
+
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	policy, err := parsePolicy(string(acl))
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to set ACL: %s", err))
+	}
+
+	if err := putPolicy(stub, ACLKey(addr), policy); err != nil {
+		return shim.Error(fmt.Sprintf("failed to set ACL: %s", err))
+	}
+
+	return policyResponse(policy)
+}
+
+// getACL returns the ACL of a contract as JSON.
+func (evmcc *EvmChaincode) getACL(stub shim.ChaincodeStubInterface, address []byte) pb.Response {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	policy, err := getPolicy(stub, ACLKey(addr))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return policyResponse(policy)
+}
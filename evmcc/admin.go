@@ -0,0 +1,164 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/crypto/sha3"
+	"github.com/hyperledger/fabric-chaincode-evm/event"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// PausedKeyPrefix prefixes the world state keys marking paused contracts.
+// Transactions which execute a paused contract or modify its state are
+// rejected when they modify any state, queries are still allowed.
+const PausedKeyPrefix = "evmcc:paused:"
+
+// PausedKey returns the world state key marking a contract as paused.
+func PausedKey(addr crypto.Address) string {
+	return PausedKeyPrefix + strings.ToLower(addr.String())
+}
+
+func isPaused(stub shim.ChaincodeStubInterface, addr crypto.Address) (bool, error) {
+	paused, err := stub.GetState(PausedKey(addr))
+	if err != nil {
+		return false, fmt.Errorf("failed to get paused state: %s", err)
+	}
+	return len(paused) != 0, nil
+}
+
+// pausedChecker returns a function reporting whether a contract is paused,
+// which reads the paused state of each contract once.
+func pausedChecker(stub shim.ChaincodeStubInterface) func(crypto.Address) (bool, error) {
+	paused := make(map[crypto.Address]bool)
+	return func(addr crypto.Address) (bool, error) {
+		if p, ok := paused[addr]; ok {
+			return p, nil
+		}
+
+		p, err := isPaused(stub, addr)
+		if err != nil {
+			return false, err
+		}
+		paused[addr] = p
+		return p, nil
+	}
+}
+
+// pause rejects transactions modifying the state of a contract until it is
+// unpaused. Only members of the admin MSP may pause contracts.
+func (evmcc *EvmChaincode) pause(stub shim.ChaincodeStubInterface, address []byte) pb.Response {
+	return evmcc.adminOperation(stub, "pause", address, func(addr crypto.Address) (string, error) {
+		return "", stub.PutState(PausedKey(addr), []byte("true"))
+	})
+}
+
+// unpause allows transactions to modify the state of a paused contract again.
+// Only members of the admin MSP may unpause contracts.
+func (evmcc *EvmChaincode) unpause(stub shim.ChaincodeStubInterface, address []byte) pb.Response {
+	return evmcc.adminOperation(stub, "unpause", address, func(addr crypto.Address) (string, error) {
+		return "", stub.DelState(PausedKey(addr))
+	})
+}
+
+// upgrade replaces the runtime code of a contract, keeping its storage and
+// balance. Only members of the admin MSP may upgrade contracts.
+func (evmcc *EvmChaincode) upgrade(stub shim.ChaincodeStubInterface, address, runtimeCode []byte) pb.Response {
+	return evmcc.adminOperation(stub, "upgrade", address, func(addr crypto.Address) (string, error) {
+		code, err := hex.DecodeString(string(runtimeCode))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode runtime code: %s", err)
+		}
+
+		if len(code) == 0 {
+			return "", fmt.Errorf("runtime code must not be empty")
+		}
+
+		// The code of the account is cleared so the EVM can initialise it again
+		state := &trackingState{StateManager: statemanager.NewStateManager(stub)}
+		acct, err := state.GetAccount(addr)
+		if err != nil {
+			return "", fmt.Errorf("failed to get account: %s", err)
+		}
+
+		acct.Code = nil
+		if err := state.UpdateAccount(acct); err != nil {
+			return "", fmt.Errorf("failed to update account: %s", err)
+		}
+
+		evmCache := evm.NewState(state, blockHashGetter(stub))
+		evmCache.InitCode(addr, code)
+		if evmErr := evmCache.Error(); evmErr != nil {
+			return "", fmt.Errorf("failed to set code: %s", evmErr)
+		}
+
+		if evmErr := evmCache.Sync(); evmErr != nil {
+			return "", fmt.Errorf("failed to sync: %s", evmErr)
+		}
+
+		return hex.EncodeToString(sha3.Sha3(code)), nil
+	})
+}
+
+// adminOperation checks that the transaction was submitted by a member of the
+// admin MSP and that a contract exists at the address, applies the operation
+// and emits an audit event recording it. The operation returns the hash of
+// the new code of the contract, if it changed.
+func (evmcc *EvmChaincode) adminOperation(stub shim.ChaincodeStubInterface, operation string, address []byte,
+	apply func(addr crypto.Address) (string, error)) pb.Response {
+	if err := checkAdmin(stub); err != nil {
+		return shim.Error(fmt.Sprintf("unauthorized: %s", err))
+	}
+
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	acct, err := statemanager.NewStateManager(stub).GetAccount(addr)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to get account: %s", err))
+	}
+
+	if acct == nil || len(acct.Code) == 0 {
+		return shim.Error(fmt.Sprintf("no contract at %s", strings.ToLower(addr.String())))
+	}
+
+	codeHash, err := apply(addr)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to %s contract: %s", operation, err))
+	}
+
+	admin, err := getCreatorMSPID(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	payload, err := json.Marshal(event.AuditEvent{
+		Operation: operation,
+		Address:   strings.ToLower(addr.String()),
+		Admin:     admin,
+		CodeHash:  codeHash,
+	})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal audit event: %s", err))
+	}
+
+	if err := stub.SetEvent(event.AuditEventPrefix+operation, payload); err != nil {
+		return shim.Error(fmt.Sprintf("failed to set audit event: %s", err))
+	}
+
+	return shim.Success(nil)
+}
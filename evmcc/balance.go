@@ -0,0 +1,112 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// mint adds to the native balance of an account, creating the account if it
+// does not exist yet. Only members of the admin MSP may mint.
+func (evmcc *EvmChaincode) mint(stub shim.ChaincodeStubInterface, address, amount []byte) pb.Response {
+	return evmcc.updateBalance(stub, address, amount, func(state *evm.State, addr crypto.Address, value uint64) {
+		if !state.Exists(addr) {
+			state.CreateAccount(addr)
+		}
+		state.AddToBalance(addr, value)
+	})
+}
+
+// burn subtracts from the native balance of an account. Only members of the
+// admin MSP may burn.
+func (evmcc *EvmChaincode) burn(stub shim.ChaincodeStubInterface, address, amount []byte) pb.Response {
+	return evmcc.updateBalance(stub, address, amount, func(state *evm.State, addr crypto.Address, value uint64) {
+		state.SubtractFromBalance(addr, value)
+	})
+}
+
+func (evmcc *EvmChaincode) updateBalance(stub shim.ChaincodeStubInterface, address, amount []byte,
+	update func(state *evm.State, addr crypto.Address, value uint64)) pb.Response {
+	if err := checkAdmin(stub); err != nil {
+		return shim.Error(fmt.Sprintf("unauthorized: %s", err))
+	}
+
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	value, err := strconv.ParseUint(string(amount), 10, 64)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to parse amount: %s", err))
+	}
+
+	evmCache := evm.NewState(statemanager.NewStateManager(stub), blockHashGetter(stub))
+	update(evmCache, addr, value)
+	if evmErr := evmCache.Error(); evmErr != nil {
+		return shim.Error(fmt.Sprintf("failed to update balance: %s", evmErr))
+	}
+
+	balance := evmCache.GetBalance(addr)
+	if evmErr := evmCache.Sync(); evmErr != nil {
+		return shim.Error(fmt.Sprintf("failed to sync: %s", evmErr))
+	}
+
+	return shim.Success([]byte(strconv.FormatUint(balance, 10)))
+}
+
+// getBalance returns the native balance of an account as a decimal string.
+// Accounts which do not exist have a zero balance.
+func (evmcc *EvmChaincode) getBalance(stub shim.ChaincodeStubInterface, address []byte) pb.Response {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	acct, err := statemanager.NewStateManager(stub).GetAccount(addr)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to get account: %s", err))
+	}
+
+	var balance uint64
+	if acct != nil {
+		balance = acct.Balance
+	}
+
+	return shim.Success([]byte(strconv.FormatUint(balance, 10)))
+}
+
+// getTxValue returns the amount of the native balance the caller transfers to
+// the callee of the current transaction. An empty value transfers nothing.
+func getTxValue(value []byte) (uint64, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(string(value), 10, 64)
+}
+
+func parseAddress(address []byte) (crypto.Address, error) {
+	a, err := hex.DecodeString(string(address))
+	if err != nil {
+		return crypto.ZeroAddress, fmt.Errorf("failed to decode address from %s: %s", string(address), err)
+	}
+
+	addr, err := crypto.AddressFromBytes(a)
+	if err != nil {
+		return crypto.ZeroAddress, fmt.Errorf("failed to get address: %s", err)
+	}
+	return addr, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// BatchCall is one of the calls of a batch transaction. Its fields are
+// encoded as the arguments of a single transaction: the hex address of the
+// callee, which is the zero address for deployments, the hex input and the
+// optional decimal gas and value.
+type BatchCall struct {
+	To    string `json:"to"`
+	Input string `json:"input"`
+	Gas   string `json:"gas,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// BatchResult is the result of one of the calls of a batch transaction: the
+// hex output of a call or the hex address of a deployed contract, along with
+// the gas used.
+type BatchResult struct {
+	Output          string `json:"output,omitempty"`
+	ContractAddress string `json:"contractAddress,omitempty"`
+	GasUsed         uint64 `json:"gasUsed"`
+}
+
+// batch runs an ordered list of calls and deployments in a single transaction.
+// Either all of them succeed and their writes are committed together, or the
+// transaction fails with the error of the first call that failed. The payload
+// of a successful response is the JSON list of results and its message the
+// total gas used.
+func (evmcc *EvmChaincode) batch(stub shim.ChaincodeStubInterface, callsArg []byte) pb.Response {
+	var calls []BatchCall
+	if err := json.Unmarshal(callsArg, &calls); err != nil {
+		return shim.Error(fmt.Sprintf("failed to unmarshal batch: %s", err))
+	}
+
+	if len(calls) == 0 {
+		return shim.Error("batch has no calls")
+	}
+
+	ex, err := newExecutor(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer ex.close()
+
+	results := make([]BatchResult, len(calls))
+	var totalGasUsed uint64
+	for i, call := range calls {
+		result, res := runBatchCall(ex, call)
+		if res.Status != shim.OK {
+			res.Message = fmt.Sprintf("batch call %d failed: %s", i, res.Message)
+			return res
+		}
+		results[i] = result
+		totalGasUsed += result.GasUsed
+	}
+
+	if err := ex.commit("batch"); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal batch results: %s", err))
+	}
+	return successWithGasUsed(resultsBytes, totalGasUsed)
+}
+
+func runBatchCall(ex *executor, call BatchCall) (BatchResult, pb.Response) {
+	calleeAddr, input, txGas, value, err := parseBatchCall(call, ex.params.GasLimit)
+	if err != nil {
+		return BatchResult{}, shim.Error(err.Error())
+	}
+	gas := txGas
+
+	if calleeAddr == crypto.ZeroAddress {
+		res := ex.deploy(input, value, &gas)
+		if res.Status != shim.OK {
+			return BatchResult{}, res
+		}
+		return BatchResult{ContractAddress: hex.EncodeToString(res.Payload), GasUsed: txGas - gas}, res
+	}
+
+	res := ex.call(calleeAddr, input, value, &gas)
+	if res.Status != shim.OK {
+		return BatchResult{}, res
+	}
+	return BatchResult{Output: hex.EncodeToString(res.Payload), GasUsed: txGas - gas}, res
+}
+
+// parseBatchCall decodes the callee, input, gas and value of a call as they
+// would be decoded from the arguments of a single transaction.
+func parseBatchCall(call BatchCall, gasLimit uint64) (crypto.Address, []byte, uint64, uint64, error) {
+	calleeAddr, err := parseAddress([]byte(call.To))
+	if err != nil {
+		return crypto.ZeroAddress, nil, 0, 0, err
+	}
+
+	input, err := hex.DecodeString(call.Input)
+	if err != nil {
+		return crypto.ZeroAddress, nil, 0, 0, fmt.Errorf("failed to decode input bytes: %s", err)
+	}
+
+	gas, err := getTxGas(gasLimit, []byte(call.Gas))
+	if err != nil {
+		return crypto.ZeroAddress, nil, 0, 0, fmt.Errorf("invalid gas: %s", err)
+	}
+
+	value, err := getTxValue([]byte(call.Value))
+	if err != nil {
+		return crypto.ZeroAddress, nil, 0, 0, fmt.Errorf("invalid value: %s", err)
+	}
+	return calleeAddr, input, gas, value, nil
+}
@@ -0,0 +1,309 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"golang.org/x/crypto/sha3"
+)
+
+// BlockHeightKey is the world state key holding the EVM block height.
+//
+// Fabric does not expose the ledger height to chaincode, so when the block
+// counter is enabled at Init evmcc keeps its own counter which is incremented
+// by every transaction that modifies EVM state. Every endorser reads the same
+// committed value, which keeps `block.number` deterministic. As every state
+// changing transaction then writes this key, only one of them can be
+// committed per Fabric block, so the counter must be enabled or disabled
+// explicitly at the first Init. When it is disabled the block height is 0.
+const BlockHeightKey = "evmcc:blockheight"
+
+// BlockHashKeyPrefix prefixes the world state keys holding the hashes of the
+// most recent blocks, which are returned by the BLOCKHASH opcode. Only the
+// last evm.MaximumAllowedBlockLookBack hashes are kept.
+const BlockHashKeyPrefix = "evmcc:blockhash:"
+
+// newParams returns the block context the EVM executes the current
+// transaction in. The height is the one the transaction will be recorded at,
+// or 0 when the block counter is disabled, and the time is the proposal
+// timestamp set by the client, which is the same for all endorsers. The gas
+// limit is the ceiling set at Init.
+func newParams(stub shim.ChaincodeStubInterface) (evm.Params, error) {
+	enabled, err := getBlockCounter(stub)
+	if err != nil {
+		return evm.Params{}, err
+	}
+
+	var blockHeight uint64
+	if enabled {
+		height, err := getBlockHeight(stub)
+		if err != nil {
+			return evm.Params{}, err
+		}
+		blockHeight = height + 1
+	}
+
+	ts, err := stub.GetTxTimestamp()
+	if err != nil {
+		return evm.Params{}, fmt.Errorf("failed to get transaction timestamp: %s", err)
+	}
+
+	gasLimit, err := getGasLimit(stub)
+	if err != nil {
+		return evm.Params{}, err
+	}
+
+	return evm.Params{
+		BlockHeight: blockHeight,
+		BlockTime:   ts.GetSeconds(),
+		GasLimit:    gasLimit,
+	}, nil
+}
+
+// getBlockHeight returns the height of the last block recorded by evmcc.
+func getBlockHeight(stub shim.ChaincodeStubInterface) (uint64, error) {
+	heightBytes, err := stub.GetState(BlockHeightKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block height: %s", err)
+	}
+
+	if len(heightBytes) == 0 {
+		return 0, nil
+	}
+
+	height, err := strconv.ParseUint(string(heightBytes), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse block height: %s", err)
+	}
+	return height, nil
+}
+
+// BlockHashKey returns the world state key of the hash of the block at height.
+func BlockHashKey(height uint64) string {
+	return BlockHashKeyPrefix + strconv.FormatUint(height, 10)
+}
+
+// BlockHash returns the hash evmcc records for a block. As evmcc blocks
+// contain a single transaction the hash is derived from its transaction id.
+func BlockHash(txID string) []byte {
+	hash := sha3.Sum256([]byte(txID))
+	return hash[:]
+}
+
+// blockHashGetter returns the function the EVM uses to look up block hashes.
+// Heights for which no hash is held, because they are outside of the
+// BLOCKHASH window or were never recorded, resolve to a zero hash.
+func blockHashGetter(stub shim.ChaincodeStubInterface) func(height uint64) []byte {
+	return func(height uint64) []byte {
+		hash, err := stub.GetState(BlockHashKey(height))
+		if err != nil {
+			// The EVM fails the execution when it receives an empty hash
+			logger.Errorf("failed to get block hash for height %d: %s", height, err)
+			return nil
+		}
+
+		if len(hash) == 0 {
+			return binary.Zero256.Bytes()
+		}
+		return hash
+	}
+}
+
+// commitTransaction increments the nonce of the sender once for each of the
+// calls of the transaction counted for it, if any, and, unless the block
+// counter is disabled and the block height is 0, records the block the
+// transaction was executed in along with its hash, and prunes the hash which
+// dropped out of the BLOCKHASH window. It is a no-op when the transaction did
+// not modify any EVM state, so read only calls do not advance the nonce or the
+// block height.
+func commitTransaction(stub shim.ChaincodeStubInterface, state *trackingState, params evm.Params, sender crypto.Address, calls uint64) error {
+	if !state.modified {
+		return nil
+	}
+
+	if calls > 0 {
+		if err := incrementNonce(state, sender, calls); err != nil {
+			return fmt.Errorf("failed to increment nonce: %s", err)
+		}
+	}
+
+	if params.BlockHeight == 0 {
+		return nil
+	}
+
+	if err := stub.PutState(BlockHeightKey, []byte(strconv.FormatUint(params.BlockHeight, 10))); err != nil {
+		return err
+	}
+
+	if err := stub.PutState(BlockHashKey(params.BlockHeight), BlockHash(stub.GetTxID())); err != nil {
+		return err
+	}
+
+	if params.BlockHeight > evm.MaximumAllowedBlockLookBack {
+		return stub.DelState(BlockHashKey(params.BlockHeight - evm.MaximumAllowedBlockLookBack))
+	}
+	return nil
+}
+
+// trackingState records whether the EVM wrote to the underlying state manager.
+// As Fabric does not return the writes of a transaction to its own reads, it
+// also keeps the accounts written so they can be updated again after the EVM
+// state has been synced. Writes to the accounts paused reports are rejected
+// instead of reaching the state manager. The first paused contract the transaction executes is kept as
+// pausedCall, which rejects all writes as a transaction which executes a
+// paused contract must not modify any state. The account at
+// NativeContextAddress is never written, its storage holds the handle of the
+// native context of the transaction and the writes of the precompiles which
+// are synced.
+type trackingState struct {
+	statemanager.StateManager
+	modified      bool
+	paused        func(crypto.Address) (bool, error)
+	rejected      error
+	pausedCall    error
+	nativeContext binary.Word256
+	writes        []*nativeWrite
+	accounts      map[crypto.Address]*acm.Account
+}
+
+// checkWrite returns the error a write to the state of address is rejected
+// with, if any, and keeps the first one as rejected.
+func (s *trackingState) checkWrite(address crypto.Address) error {
+	s.modified = true
+	err := s.pausedCall
+	if err == nil && s.paused != nil {
+		var paused bool
+		paused, err = s.paused(address)
+		if err == nil && paused {
+			err = fmt.Errorf("contract %s is paused", strings.ToLower(address.String()))
+		}
+	}
+
+	if err != nil && s.rejected == nil {
+		s.rejected = err
+	}
+	return err
+}
+
+// checkCall keeps the first paused contract executed by the transaction, after
+// which all writes are rejected.
+func (s *trackingState) checkCall(address crypto.Address) error {
+	if s.paused == nil || s.pausedCall != nil {
+		return nil
+	}
+
+	paused, err := s.paused(address)
+	if err != nil {
+		return err
+	}
+	if paused {
+		s.pausedCall = fmt.Errorf("contract %s is paused", strings.ToLower(address.String()))
+	}
+	return nil
+}
+
+func (s *trackingState) GetAccount(address crypto.Address) (*acm.Account, error) {
+	if address == NativeContextAddress {
+		return &acm.Account{Address: NativeContextAddress}, nil
+	}
+	if acct, ok := s.accounts[address]; ok {
+		return acct.Copy(), nil
+	}
+	return s.StateManager.GetAccount(address)
+}
+
+func (s *trackingState) UpdateAccount(updatedAccount *acm.Account) error {
+	if updatedAccount.Address == NativeContextAddress {
+		if updatedAccount.Balance != 0 || len(updatedAccount.Code) != 0 {
+			return fmt.Errorf("address %s is reserved", strings.ToLower(NativeContextAddress.String()))
+		}
+		return nil
+	}
+	if err := s.checkWrite(updatedAccount.Address); err != nil {
+		return err
+	}
+	if err := s.StateManager.UpdateAccount(updatedAccount); err != nil {
+		return err
+	}
+
+	if s.accounts == nil {
+		s.accounts = make(map[crypto.Address]*acm.Account)
+	}
+	s.accounts[updatedAccount.Address] = updatedAccount.Copy()
+	return nil
+}
+
+func (s *trackingState) RemoveAccount(address crypto.Address) error {
+	if err := s.checkWrite(address); err != nil {
+		return err
+	}
+	if err := s.StateManager.RemoveAccount(address); err != nil {
+		return err
+	}
+
+	if s.accounts == nil {
+		s.accounts = make(map[crypto.Address]*acm.Account)
+	}
+	s.accounts[address] = nil
+	return nil
+}
+
+func (s *trackingState) GetStorage(address crypto.Address, key binary.Word256) (binary.Word256, error) {
+	if address == NativeContextAddress {
+		// The writes of the precompiles are only held by the EVM state
+		if key == binary.Zero256 {
+			return s.nativeContext, nil
+		}
+		return binary.Zero256, nil
+	}
+	return s.StateManager.GetStorage(address, key)
+}
+
+func (s *trackingState) SetStorage(address crypto.Address, key, value binary.Word256) error {
+	if address == NativeContextAddress {
+		// The handle at key 0 is synced along with the writes once it was read
+		index := binary.Uint64FromWord256(key)
+		if index == 0 {
+			return nil
+		}
+		if index > uint64(len(s.writes)) {
+			return fmt.Errorf("address %s is reserved", strings.ToLower(NativeContextAddress.String()))
+		}
+		s.writes[index-1].synced = true
+		return nil
+	}
+	if err := s.checkWrite(address); err != nil {
+		return err
+	}
+	return s.StateManager.SetStorage(address, key, value)
+}
+
+// applyWrites applies the writes of the precompiles which were synced, in the
+// order they were made. It fails when a write which could not wait for the
+// sync was not synced, as it cannot be undone.
+func (s *trackingState) applyWrites() error {
+	for _, w := range s.writes {
+		switch {
+		case w.apply == nil && !w.synced:
+			return fmt.Errorf("a reverted call made a write outside of the EVM state which cannot be undone, such as a chaincode invocation")
+		case w.apply != nil && w.synced:
+			if err := w.apply(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
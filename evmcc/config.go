@@ -0,0 +1,199 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// GasLimitKey is the world state key holding the maximum amount of gas a
+// single transaction may use.
+const GasLimitKey = "evmcc:gaslimit"
+
+// DefaultGasLimit is the gas ceiling used when none has been set at Init.
+const DefaultGasLimit uint64 = 10000
+
+// AdminKey is the world state key holding the MSP ID whose members may
+// administer evmcc, such as minting and burning native balances.
+const AdminKey = "evmcc:admin"
+
+// ForkKey is the world state key holding the name of the EVM fork whose
+// opcodes are enabled, see forks.
+const ForkKey = "evmcc:fork"
+
+// ChainIDKey is the world state key holding the chain ID returned by the
+// CHAINID opcode.
+const ChainIDKey = "evmcc:chainid"
+
+// BlockCounterKey is the world state key marking whether evmcc keeps its own
+// block height, see BlockHeightKey.
+const BlockCounterKey = "evmcc:blockcounter"
+
+// EventNameKey is the world state key holding the template of the names of
+// the Fabric events of EVM transactions, see eventmanager.ValidateNameTemplate.
+const EventNameKey = "evmcc:eventname"
+
+// EventPayloadKey is the world state key holding the format of the payloads
+// of the Fabric events of EVM transactions, see eventPayloads.
+const EventPayloadKey = "evmcc:eventpayload"
+
+// initOptions are the settings which can be passed to Init as `key=value`
+// arguments. Each setting is stored in world state, settings which are not
+// provided keep their current value across chaincode upgrades. The block
+// counter must be set at the first Init, as `block.number` is always 0 when
+// it is disabled, see BlockHeightKey.
+var initOptions = map[string]func(stub shim.ChaincodeStubInterface, value string) error{
+	"gaslimit":     setGasLimit,
+	"admin":        setAdmin,
+	"fork":         setFork,
+	"chainid":      setChainID,
+	"deployers":    setDeployers,
+	"blockcounter": setBlockCounter,
+	"eventname":    setEventName,
+	"eventpayload": setEventPayload,
+}
+
+func applyInitOptions(stub shim.ChaincodeStubInterface, args [][]byte) error {
+	blockCounterSet := false
+	for _, arg := range args {
+		kv := strings.SplitN(string(arg), "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("expected argument of the form key=value, got %q", string(arg))
+		}
+
+		setOption, ok := initOptions[kv[0]]
+		if !ok {
+			return fmt.Errorf("unknown option %q", kv[0])
+		}
+
+		if err := setOption(stub, kv[1]); err != nil {
+			return fmt.Errorf("failed to set %s: %s", kv[0], err)
+		}
+		blockCounterSet = blockCounterSet || kv[0] == "blockcounter"
+	}
+
+	if blockCounterSet {
+		return nil
+	}
+
+	// Writes are not returned to the reads of the same transaction, so only
+	// the value of a previous Init can be read
+	blockCounter, err := stub.GetState(BlockCounterKey)
+	if err != nil {
+		return fmt.Errorf("failed to get block counter: %s", err)
+	}
+	if len(blockCounter) == 0 {
+		return fmt.Errorf("option blockcounter must be set to true or false, block.number is always 0 when it is false")
+	}
+	return nil
+}
+
+func setGasLimit(stub shim.ChaincodeStubInterface, value string) error {
+	gasLimit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if gasLimit == 0 {
+		return fmt.Errorf("gas limit must be greater than 0")
+	}
+
+	return stub.PutState(GasLimitKey, []byte(strconv.FormatUint(gasLimit, 10)))
+}
+
+func setAdmin(stub shim.ChaincodeStubInterface, value string) error {
+	if value == "" {
+		return fmt.Errorf("admin MSP ID must not be empty")
+	}
+
+	return stub.PutState(AdminKey, []byte(value))
+}
+
+func setFork(stub shim.ChaincodeStubInterface, value string) error {
+	if _, ok := forks[value]; !ok {
+		return fmt.Errorf("unknown fork %q", value)
+	}
+
+	return stub.PutState(ForkKey, []byte(value))
+}
+
+func setChainID(stub shim.ChaincodeStubInterface, value string) error {
+	chainID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState(ChainIDKey, []byte(strconv.FormatUint(chainID, 10)))
+}
+
+func setBlockCounter(stub shim.ChaincodeStubInterface, value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState(BlockCounterKey, []byte(strconv.FormatBool(enabled)))
+}
+
+// getBlockCounter returns whether evmcc keeps its own block height.
+func getBlockCounter(stub shim.ChaincodeStubInterface) (bool, error) {
+	enabledBytes, err := stub.GetState(BlockCounterKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to get block counter: %s", err)
+	}
+
+	if len(enabledBytes) == 0 {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(string(enabledBytes))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse block counter: %s", err)
+	}
+	return enabled, nil
+}
+
+// getGasLimit returns the gas ceiling for transactions.
+func getGasLimit(stub shim.ChaincodeStubInterface) (uint64, error) {
+	gasLimitBytes, err := stub.GetState(GasLimitKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get gas limit: %s", err)
+	}
+
+	if len(gasLimitBytes) == 0 {
+		return DefaultGasLimit, nil
+	}
+
+	gasLimit, err := strconv.ParseUint(string(gasLimitBytes), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse gas limit: %s", err)
+	}
+	return gasLimit, nil
+}
+
+// getTxGas returns the gas available to the current transaction. A
+// transaction may request less gas than the ceiling, requests for more are
+// capped at the ceiling. An empty request leaves the gas at the ceiling.
+func getTxGas(gasLimit uint64, requested []byte) (uint64, error) {
+	if len(requested) == 0 {
+		return gasLimit, nil
+	}
+
+	gas, err := strconv.ParseUint(string(requested), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse gas: %s", err)
+	}
+
+	if gas > gasLimit {
+		return gasLimit, nil
+	}
+	return gas, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// DeployersKey is the world state key holding the policy of the identities
+// allowed to deploy contracts. Everyone may deploy when it is not set.
+const DeployersKey = "evmcc:deployers"
+
+func setDeployers(stub shim.ChaincodeStubInterface, value string) error {
+	policy, err := parsePolicy(value)
+	if err != nil {
+		return err
+	}
+	return putPolicy(stub, DeployersKey, policy)
+}
+
+// checkDeployer returns an error unless the transaction was submitted by an
+// identity allowed to deploy contracts.
+func checkDeployer(stub shim.ChaincodeStubInterface) error {
+	policy, err := getPolicy(stub, DeployersKey)
+	if err != nil {
+		return err
+	}
+
+	if policy.isEmpty() {
+		return nil
+	}
+
+	identity, err := getCreator(stub)
+	if err != nil {
+		return err
+	}
+
+	allowed, err := policy.allows(identity)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return fmt.Errorf("identity of %s is not allowed to deploy contracts", identity.mspID)
+	}
+	return nil
+}
+
+// setDeployers replaces the deployers policy and returns it as JSON. Only
+// members of the admin MSP may change it, an empty list allows everyone to
+// deploy.
+func (evmcc *EvmChaincode) setDeployers(stub shim.ChaincodeStubInterface, deployers []byte) pb.Response {
+	if err := checkAdmin(stub); err != nil {
+		return shim.Error(fmt.Sprintf("unauthorized: %s", err))
+	}
+
+	policy, err := parsePolicy(string(deployers))
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to set deployers: %s", err))
+	}
+
+	if err := putPolicy(stub, DeployersKey, policy); err != nil {
+		return shim.Error(fmt.Sprintf("failed to set deployers: %s", err))
+	}
+
+	return policyResponse(policy)
+}
+
+// getDeployers returns the deployers policy as JSON.
+func (evmcc *EvmChaincode) getDeployers(stub shim.ChaincodeStubInterface) pb.Response {
+	policy, err := getPolicy(stub, DeployersKey)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return policyResponse(policy)
+}
+
+func policyResponse(policy *Policy) pb.Response {
+	policyBytes, err := json.Marshal(policy)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal policy: %s", err))
+	}
+	return shim.Success(policyBytes)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+)
+
+// EndorsementPrecompileAddress is the address of the precompile through which
+// contracts set key-level endorsement policies on their account and storage.
+// Its interface is FabricEndorsement in solidity/FabricEndorsement.sol.
+var EndorsementPrecompileAddress = crypto.Address{18: 0xfa, 19: 0xb5}
+
+func init() {
+	registerPrecompile(EndorsementPrecompileAddress, "FabricEndorsement", map[string]precompileFunction{
+		"setAccountEndorsers(string[])":                setAccountEndorsers,
+		"setStorageEndorsers(bytes32,string[])":        setStorageEndorsers,
+		"setAccountValidationParameter(bytes)":         setAccountValidationParameter,
+		"setStorageValidationParameter(bytes32,bytes)": setStorageValidationParameter,
+		"getAccountValidationParameter()":              getAccountValidationParameter,
+		"getStorageValidationParameter(bytes32)":       getStorageValidationParameter,
+	})
+}
+
+// The policies are set on the world state keys of the contract calling the
+// precompile, so contracts cannot change the policies of other contracts.
+
+func setAccountEndorsers(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	mspIDs, err := args.StringArray(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MSP IDs: %s", err)
+	}
+
+	ep, err := endorsementPolicy(mspIDs)
+	if err != nil {
+		return nil, err
+	}
+	return nil, setValidationParameter(ctx, caller, statemanager.AccountKey(caller), ep)
+}
+
+func setStorageEndorsers(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	slot, err := args.Word(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage slot: %s", err)
+	}
+
+	mspIDs, err := args.StringArray(1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MSP IDs: %s", err)
+	}
+
+	ep, err := endorsementPolicy(mspIDs)
+	if err != nil {
+		return nil, err
+	}
+	return nil, setValidationParameter(ctx, caller, statemanager.StorageKey(caller, slot), ep)
+}
+
+func setAccountValidationParameter(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	ep, err := args.Bytes(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation parameter: %s", err)
+	}
+	return nil, setValidationParameter(ctx, caller, statemanager.AccountKey(caller), ep)
+}
+
+func setStorageValidationParameter(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	slot, err := args.Word(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage slot: %s", err)
+	}
+
+	ep, err := args.Bytes(1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation parameter: %s", err)
+	}
+	return nil, setValidationParameter(ctx, caller, statemanager.StorageKey(caller, slot), ep)
+}
+
+func getAccountValidationParameter(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	ep, err := ctx.stub.GetStateValidationParameter(statemanager.AccountKey(caller))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validation parameter: %s", err)
+	}
+	return abiEncode(ep), nil
+}
+
+func getStorageValidationParameter(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	slot, err := args.Word(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage slot: %s", err)
+	}
+
+	ep, err := ctx.stub.GetStateValidationParameter(statemanager.StorageKey(caller, slot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validation parameter: %s", err)
+	}
+	return abiEncode(ep), nil
+}
+
+func setValidationParameter(ctx *nativeContext, caller crypto.Address, key string, ep []byte) error {
+	return ctx.write(caller, fmt.Sprintf("set validation parameter of %s", key), func() error {
+		if err := ctx.stub.SetStateValidationParameter(key, ep); err != nil {
+			return fmt.Errorf("failed to set validation parameter: %s", err)
+		}
+		return nil
+	})
+}
+
+// endorsementPolicy returns the validation parameter of a policy which
+// requires an endorsement from a peer of each of the organizations, as built by
+// the statebased package of Fabric. An empty list of organizations removes
+// the policy.
+func endorsementPolicy(mspIDs []string) ([]byte, error) {
+	if len(mspIDs) == 0 {
+		return nil, nil
+	}
+
+	sorted := append([]string(nil), mspIDs...)
+	sort.Strings(sorted)
+
+	var (
+		principals []*msp.MSPPrincipal
+		rules      []*common.SignaturePolicy
+	)
+	for i, mspID := range sorted {
+		if i > 0 && mspID == sorted[i-1] {
+			continue
+		}
+
+		role, err := proto.Marshal(&msp.MSPRole{MspIdentifier: mspID, Role: msp.MSPRole_PEER})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal role of %s: %s", mspID, err)
+		}
+
+		rules = append(rules, &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_SignedBy{SignedBy: int32(len(principals))},
+		})
+		principals = append(principals, &msp.MSPPrincipal{
+			PrincipalClassification: msp.MSPPrincipal_ROLE,
+			Principal:               role,
+		})
+	}
+
+	ep, err := proto.Marshal(&common.SignaturePolicyEnvelope{
+		Rule: &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_NOutOf_{
+				NOutOf: &common.SignaturePolicy_NOutOf{N: int32(len(rules)), Rules: rules},
+			},
+		},
+		Identities: principals,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal endorsement policy: %s", err)
+	}
+	return ep, nil
+}
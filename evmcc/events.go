@@ -0,0 +1,91 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-evm/eventmanager"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// LogsPayload is the format of the event payloads used when none has been set
+// at Init: the list of logs.
+const LogsPayload = "logs"
+
+// StructuredPayload is the format of event payloads which are an object of
+// logs and calls, which are not recorded.
+const StructuredPayload = "structured"
+
+// CallsPayload is the format of structured event payloads which record the
+// calls made by contracts, without their input and output.
+const CallsPayload = "calls"
+
+// CallDataPayload is the format of structured event payloads which record the
+// calls made by contracts along with their input and output.
+const CallDataPayload = "calldata"
+
+// eventPayload sets how the event manager encodes the payload of events.
+type eventPayload struct {
+	structured, calls, callData bool
+}
+
+// eventPayloads maps the formats of the event payloads to their settings.
+var eventPayloads = map[string]eventPayload{
+	LogsPayload:       {},
+	StructuredPayload: {structured: true},
+	CallsPayload:      {structured: true, calls: true},
+	CallDataPayload:   {structured: true, calls: true, callData: true},
+}
+
+func setEventName(stub shim.ChaincodeStubInterface, value string) error {
+	if err := eventmanager.ValidateNameTemplate(value); err != nil {
+		return err
+	}
+
+	return stub.PutState(EventNameKey, []byte(value))
+}
+
+func setEventPayload(stub shim.ChaincodeStubInterface, value string) error {
+	if _, ok := eventPayloads[value]; !ok {
+		return fmt.Errorf("unknown event payload %q", value)
+	}
+
+	return stub.PutState(EventPayloadKey, []byte(value))
+}
+
+// newEventManager returns the event manager of the transaction of stub, which
+// names and encodes its event as set at Init.
+func newEventManager(stub shim.ChaincodeStubInterface) (*eventmanager.EventManager, error) {
+	nameTemplate, err := stub.GetState(EventNameKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event name: %s", err)
+	}
+
+	payload, err := stub.GetState(EventPayloadKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event payload: %s", err)
+	}
+
+	format := string(payload)
+	if format == "" {
+		format = LogsPayload
+	}
+
+	settings, ok := eventPayloads[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown event payload %q", format)
+	}
+
+	return &eventmanager.EventManager{
+		Stub:              stub,
+		NameTemplate:      string(nameTemplate),
+		StructuredPayload: settings.structured,
+		RecordCalls:       settings.calls,
+		RecordCallData:    settings.callData,
+	}, nil
+}
@@ -9,16 +9,14 @@ package main
 import (
 	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/hyperledger/burrow/acm"
 	"github.com/hyperledger/burrow/crypto"
-	"github.com/hyperledger/burrow/execution/evm"
 	"github.com/hyperledger/burrow/logging"
 	"github.com/hyperledger/burrow/permission"
 	"github.com/hyperledger/fabric-chaincode-evm/address"
-	"github.com/hyperledger/fabric-chaincode-evm/eventmanager"
-	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -40,26 +38,74 @@ var evmLogger = logging.NewNoopLogger()
 type EvmChaincode struct{}
 
 func (evmcc *EvmChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
-	logger.Debugf("Init evmcc, it's no-op")
+	logger.Debugf("Init evmcc")
+	if err := applyInitOptions(stub, stub.GetArgs()); err != nil {
+		return shim.Error(fmt.Sprintf("failed to initialize evmcc: %s", err))
+	}
 	return shim.Success(nil)
 }
 
 func (evmcc *EvmChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
-	// We always expect 2 args: 'callee address, input data' or ' getCode ,  contract address'
+	// We expect 2 args: 'callee address, input data' or ' getCode ,  contract address'
+	// A third arg can be provided along with the input data to limit the gas of the transaction
+	// and a fourth arg to transfer native balance to the callee
 	args := stub.GetArgs()
 
 	if len(args) == 1 {
-		if string(args[0]) == "account" {
+		switch string(args[0]) {
+		case "account":
 			return evmcc.account(stub)
+		case "getDeployers":
+			return evmcc.getDeployers(stub)
 		}
 	}
 
-	if len(args) != 2 {
-		return shim.Error(fmt.Sprintf("expects 2 args, got %d : %s", len(args), string(args[0])))
+	if len(args) < 2 || len(args) > 4 {
+		return shim.Error(fmt.Sprintf("expects between 2 and 4 args, got %d : %s", len(args), string(args[0])))
+	}
+
+	if len(args) == 2 {
+		switch string(args[0]) {
+		case "getCode":
+			return evmcc.getCode(stub, args[1])
+		case "getBalance":
+			return evmcc.getBalance(stub, args[1])
+		case "getNonce":
+			return evmcc.getNonce(stub, args[1])
+		case "getAccount":
+			return evmcc.getAccount(stub, args[1])
+		case "setDeployers":
+			return evmcc.setDeployers(stub, args[1])
+		case "getACL":
+			return evmcc.getACL(stub, args[1])
+		case "pause":
+			return evmcc.pause(stub, args[1])
+		case "unpause":
+			return evmcc.unpause(stub, args[1])
+		case "batch":
+			return evmcc.batch(stub, args[1])
+		case "multicall":
+			return evmcc.multicall(stub, args[1])
+		case "trace":
+			return evmcc.trace(stub, args[1], nil)
+		}
 	}
 
-	if string(args[0]) == "getCode" {
-		return evmcc.getCode(stub, args[1])
+	if len(args) == 3 {
+		switch string(args[0]) {
+		case "mint":
+			return evmcc.mint(stub, args[1], args[2])
+		case "burn":
+			return evmcc.burn(stub, args[1], args[2])
+		case "setACL":
+			return evmcc.setACL(stub, args[1], args[2])
+		case "upgrade":
+			return evmcc.upgrade(stub, args[1], args[2])
+		case "getStorageAt":
+			return evmcc.getStorageAt(stub, args[1], args[2])
+		case "trace":
+			return evmcc.trace(stub, args[1], args[2])
+		}
 	}
 
 	c, err := hex.DecodeString(string(args[0]))
@@ -72,97 +118,76 @@ func (evmcc *EvmChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		return shim.Error(fmt.Sprintf("failed to get callee address: %s", err))
 	}
 
-	// get caller account from creator public key
-	callerAddr, err := getCallerAddress(stub)
+	// get input bytes from args[1], completed by the transient map
+	input, err := hex.DecodeString(string(args[1]))
 	if err != nil {
-		return shim.Error(fmt.Sprintf("failed to get caller address: %s", err))
+		return shim.Error(fmt.Sprintf("failed to decode input bytes: %s", err))
 	}
 
-	// get input bytes from args[1]
-	input, err := hex.DecodeString(string(args[1]))
+	input, err = transientInput(stub, input)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("failed to decode input bytes: %s", err))
+		return shim.Error(err.Error())
 	}
 
-	var gas uint64 = 10000
-	state := statemanager.NewStateManager(stub)
-	evmCache := evm.NewState(state, func(height uint64) []byte {
-		// This function is to be used to return the block hash
-		// Currently EVMCC does not support the BLOCKHASH opcode.
-		// This function is only used for that opcode and will not
-		// affect execution if BLOCKHASH is not called.
-		panic("Block Hash shouldn't be called")
-	})
-	eventSink := &eventmanager.EventManager{Stub: stub}
-	nonce := crypto.Nonce(callerAddr, []byte(stub.GetTxID()))
-	vm := evm.NewVM(newParams(), callerAddr, nonce, evmLogger)
+	ex, err := newExecutor(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer ex.close()
 
-	if calleeAddr == crypto.ZeroAddress {
-		logger.Debugf("Deploy contract")
-
-		logger.Debugf("Contract nonce number = %d", nonce)
-		contractAddr := crypto.NewContractAddress(callerAddr, nonce)
-		// Contract account needs to be created before setting code to it
-		evmCache.CreateAccount(contractAddr)
-		if evmErr := evmCache.Error(); evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to create the contract account: %s ", evmErr))
-		}
+	var gasArg, valueArg []byte
+	if len(args) > 2 {
+		gasArg = args[2]
+	}
+	if len(args) > 3 {
+		valueArg = args[3]
+	}
 
-		evmCache.SetPermission(contractAddr, ContractPermFlags, true)
-		if evmErr := evmCache.Error(); evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to set contract account permissions: %s ", evmErr))
-		}
+	txGas, err := getTxGas(ex.params.GasLimit, gasArg)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("invalid gas: %s", err))
+	}
+	gas := txGas
 
-		rtCode, evmErr := vm.Call(evmCache, eventSink, callerAddr, contractAddr, input, input, 0, &gas)
-		if evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to deploy code: %s", evmErr))
-		}
-		if rtCode == nil {
-			return shim.Error(fmt.Sprintf("nil bytecode"))
-		}
+	value, err := getTxValue(valueArg)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("invalid value: %s", err))
+	}
 
-		evmCache.InitCode(contractAddr, rtCode)
-		if evmErr := evmCache.Error(); evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to update contract account: %s", evmErr))
+	if calleeAddr == crypto.ZeroAddress {
+		res := ex.deploy(input, value, &gas)
+		if res.Status != shim.OK {
+			return res
 		}
 
 		// Passing the first 8 bytes contract address just created
-		err := eventSink.Flush(string(contractAddr.Bytes()[0:8]))
-		if err != nil {
-			return shim.Error(fmt.Sprintf("error in Flush: %s", err))
-		}
-
-		if evmErr := evmCache.Sync(); evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to sync: %s", evmErr))
+		if err := ex.commit(string(res.Payload[0:8])); err != nil {
+			return shim.Error(err.Error())
 		}
 		// return encoded hex bytes for human-readability
-		return shim.Success([]byte(hex.EncodeToString(contractAddr.Bytes())))
+		return successWithGasUsed([]byte(hex.EncodeToString(res.Payload)), txGas-gas)
 	} else {
-		logger.Debugf("Invoke contract at %x", calleeAddr.Bytes())
-
-		calleeCode := evmCache.GetCode(calleeAddr)
-		if evmErr := evmCache.Error(); evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to retrieve contract code: %s", evmErr))
-		}
-
-		output, evmErr := vm.Call(evmCache, eventSink, callerAddr, calleeAddr, calleeCode, input, 0, &gas)
-		if evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to execute contract: %s", evmErr))
+		res := ex.call(calleeAddr, input, value, &gas)
+		if res.Status != shim.OK {
+			return res
 		}
 
 		// Passing the function hash of the method that has triggered the event
-		// The function hash is the first 8 bytes of the Input argument
-		err := eventSink.Flush(string(args[1][0:8]))
-		if err != nil {
-			return shim.Error(fmt.Sprintf("error in Flush: %s", err))
+		// The function hash is the first 8 bytes of the Input argument, or of
+		// the hex input when it is in the transient map, plain value transfers
+		// have no input
+		functionHash := args[1]
+		if len(functionHash) == 0 {
+			functionHash = []byte(hex.EncodeToString(input))
 		}
-
-		// Sync is required for evm to send writes to the statemanager.
-		if evmErr := evmCache.Sync(); evmErr != nil {
-			return shim.Error(fmt.Sprintf("failed to sync: %s", evmErr))
+		if len(functionHash) > 8 {
+			functionHash = functionHash[0:8]
+		}
+		if err := ex.commit(string(functionHash)); err != nil {
+			return shim.Error(err.Error())
 		}
 
-		return shim.Success(output)
+		return successWithGasUsed(res.Payload, txGas-gas)
 	}
 }
 
@@ -202,11 +227,14 @@ func (evmcc *EvmChaincode) account(stub shim.ChaincodeStubInterface) pb.Response
 	return shim.Success([]byte(callerAddr.String()))
 }
 
-func newParams() evm.Params {
-	return evm.Params{
-		BlockHeight: 0,
-		BlockTime:   0,
-		GasLimit:    0,
+// successWithGasUsed returns a successful response carrying the amount of gas
+// the transaction consumed as a decimal string in the message, which leaves
+// the payload as the output of the transaction.
+func successWithGasUsed(payload []byte, gasUsed uint64) pb.Response {
+	return pb.Response{
+		Status:  shim.OK,
+		Message: strconv.FormatUint(gasUsed, 10),
+		Payload: payload,
 	}
 }
 
@@ -9,19 +9,29 @@ package main_test
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/binary"
 	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/crypto/sha3"
 	"github.com/hyperledger/burrow/permission"
 	"github.com/hyperledger/fabric-chaincode-evm/address"
 	"github.com/hyperledger/fabric-chaincode-evm/event"
 	evm "github.com/hyperledger/fabric-chaincode-evm/evmcc"
 	evmcc_mocks "github.com/hyperledger/fabric-chaincode-evm/mocks/evmcc"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	"github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -62,6 +72,25 @@ var _ = Describe("evmcc", func() {
 			return nil
 		}
 
+		stub.GetStateByRangeStub = func(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+			var kvs []*queryresult.KV
+			for key, value := range fakeLedger {
+				if key >= startKey && key < endKey {
+					kvs = append(kvs, &queryresult.KV{Key: key, Value: value})
+				}
+			}
+			sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+			iter := &evmcc_mocks.MockStateQueryIterator{}
+			iter.HasNextStub = func() bool { return len(kvs) > 0 }
+			iter.NextStub = func() (*queryresult.KV, error) {
+				kv := kvs[0]
+				kvs = kvs[1:]
+				return kv, nil
+			}
+			return iter, nil
+		}
+
 		//TxID is used to create a nonce which is used to create contract addresses
 		stub.GetTxIDStub = func() string {
 			nonce = nonce + 1
@@ -72,10 +101,208 @@ var _ = Describe("evmcc", func() {
 
 	Describe("Init", func() {
 		It("returns an OK response", func() {
+			stub.GetArgsReturns([][]byte{[]byte("blockcounter=true")})
 			res := evmcc.Init(stub)
 			Expect(res.Status).To(Equal(int32(shim.OK)))
 			Expect(res.Payload).To(Equal([]byte(nil)))
 		})
+
+		It("requires the block counter to be set at the first Init", func() {
+			res := evmcc.Init(stub)
+			Expect(res.Status).To(Equal(int32(shim.ERROR)))
+			Expect(res.Message).To(Equal("failed to initialize evmcc: option blockcounter must be set to true or false, block.number is always 0 when it is false"))
+
+			stub.GetArgsReturns([][]byte{[]byte("admin=TestOrg")})
+			res = evmcc.Init(stub)
+			Expect(res.Status).To(Equal(int32(shim.ERROR)))
+		})
+
+		It("keeps the block counter setting when the chaincode is upgraded with the default arguments", func() {
+			stub.GetArgsReturns([][]byte{[]byte("blockcounter=true")})
+			res := evmcc.Init(stub)
+			Expect(res.Status).To(Equal(int32(shim.OK)))
+
+			stub.GetArgsReturns(nil)
+			res = evmcc.Init(stub)
+			Expect(res.Status).To(Equal(int32(shim.OK)))
+			Expect(fakeLedger[evm.BlockCounterKey]).To(Equal([]byte("true")))
+		})
+
+		Context("when a gas limit is provided", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("gaslimit=100000"), []byte("blockcounter=false")})
+			})
+
+			It("stores the gas limit", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.GasLimitKey]).To(Equal([]byte("100000")))
+			})
+		})
+
+		Context("when the gas limit is not a positive number", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("gaslimit=0")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to set gaslimit"))
+				Expect(fakeLedger).ToNot(HaveKey(evm.GasLimitKey))
+			})
+		})
+
+		Context("when an admin is provided", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("admin=TestOrg"), []byte("blockcounter=false")})
+			})
+
+			It("stores the admin MSP ID", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.AdminKey]).To(Equal([]byte("TestOrg")))
+			})
+		})
+
+		Context("when the admin is empty", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("admin=")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to set admin"))
+				Expect(fakeLedger).ToNot(HaveKey(evm.AdminKey))
+			})
+		})
+
+		Context("when a fork and a chain ID are provided", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("fork=istanbul"), []byte("chainid=1337"), []byte("blockcounter=false")})
+			})
+
+			It("stores the fork and the chain ID", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.ForkKey]).To(Equal([]byte("istanbul")))
+				Expect(fakeLedger[evm.ChainIDKey]).To(Equal([]byte("1337")))
+			})
+		})
+
+		Context("when the fork is unknown", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("fork=frontier")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring(`failed to set fork: unknown fork "frontier"`))
+				Expect(fakeLedger).ToNot(HaveKey(evm.ForkKey))
+			})
+		})
+
+		Context("when the block counter is enabled", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("blockcounter=true")})
+			})
+
+			It("stores the block counter setting", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.BlockCounterKey]).To(Equal([]byte("true")))
+			})
+		})
+
+		Context("when the block counter setting is not a boolean", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("blockcounter=sometimes")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to set blockcounter"))
+				Expect(fakeLedger).ToNot(HaveKey(evm.BlockCounterKey))
+			})
+		})
+
+		Context("when deployers are provided", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("deployers=Org1MSP, Org2MSP,evm.deployer=true"), []byte("blockcounter=false")})
+			})
+
+			It("stores the deployers policy", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.DeployersKey]).To(MatchJSON(`{"mspids":["Org1MSP","Org2MSP"],"attributes":{"evm.deployer":"true"}}`))
+			})
+		})
+
+		Context("when an event name and an event payload are provided", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("eventname=evm:{address}:{topic0}"), []byte("eventpayload=structured"), []byte("blockcounter=false")})
+			})
+
+			It("stores the event name and the event payload", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.EventNameKey]).To(Equal([]byte("evm:{address}:{topic0}")))
+				Expect(fakeLedger[evm.EventPayloadKey]).To(Equal([]byte("structured")))
+			})
+		})
+
+		Context("when the event name has an unknown placeholder", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("eventname=evm:{contract}")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to set eventname: unknown placeholder {contract}"))
+				Expect(fakeLedger).ToNot(HaveKey(evm.EventNameKey))
+			})
+		})
+
+		Context("when the event payload is unknown", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("eventpayload=xml")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring(`failed to set eventpayload: unknown event payload "xml"`))
+				Expect(fakeLedger).ToNot(HaveKey(evm.EventPayloadKey))
+			})
+		})
+
+		Context("when an argument is not of the form key=value", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("gaslimit")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("expected argument of the form key=value"))
+			})
+		})
+
+		Context("when an unknown option is provided", func() {
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("unknown=value")})
+			})
+
+			It("returns an error", func() {
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring(`unknown option "unknown"`))
+			})
+		})
 	})
 
 	Describe("Invoke", func() {
@@ -92,6 +319,23 @@ KbpP3GF9/crEahpXXRajTTBLMA4GA1UdDwEB/wQEAwIHgDAMBgNVHRMBAf8EAjAA
 MCsGA1UdIwQkMCKAIEvLfQX685pz+rh2q5yCA7e0a/a5IGDuJVHRWfp++HThMAoG
 CCqGSM49BAMCA0gAMEUCIH5H9W3tsCrti6tsN9UfY1eeTKtExf/abXhfqfVeRChk
 AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
+-----END CERTIFICATE-----`
+
+			// user1Cert holds the attributes {"evm.deployer":"true","hf.EnrollmentID":"user1"}
+			// and the organizational units client and evm
+			user1Cert = `-----BEGIN CERTIFICATE-----
+MIICEzCCAbigAwIBAgIUZDgOVS+PonMYVAcq+lqrXXpxXyQwCgYIKoZIzj0EAwIw
+UjELMAkGA1UEBhMCVVMxFDASBgNVBAoMC2V4YW1wbGUuY29tMQ8wDQYDVQQLDAZj
+bGllbnQxDDAKBgNVBAsMA2V2bTEOMAwGA1UEAwwFdXNlcjEwIBcNMjYxMDE3MDA1
+MjMzWhgPMjEyNjA5MjMwMDUyMzNaMFIxCzAJBgNVBAYTAlVTMRQwEgYDVQQKDAtl
+eGFtcGxlLmNvbTEPMA0GA1UECwwGY2xpZW50MQwwCgYDVQQLDANldm0xDjAMBgNV
+BAMMBXVzZXIxMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAET8GTXB7I9oghe171
+ZqOhHH8Y6eJI3hYlyBu9MfVLOglNHKzV43shk8Gsz3mojRi5bH70g/IDwbi4wKXg
+TDinWKNqMGgwRwYIKgMEBQYHCAEEO3siYXR0cnMiOnsiZXZtLmRlcGxveWVyIjoi
+dHJ1ZSIsImhmLkVucm9sbG1lbnRJRCI6InVzZXIxIn19MB0GA1UdDgQWBBRDicTm
+fW4n8Ul+oAXPa91acHDcDDAKBggqhkjOPQQDAgNJADBGAiEAseVoOgbcBXA/klQA
+FiPI7pk62CWubD+G6zumfCWcobECIQCyIuAdopOFmt2fDC6h5p/XWgm1U5OC4JnM
+dAege8oNPg==
 -----END CERTIFICATE-----`
 
 			creator = marshalCreator("TestOrg", []byte(user0Cert))
@@ -111,6 +355,55 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 
 			deployCode  = []byte("6060604052341561000f57600080fd5b60d38061001d6000396000f3006060604052600436106049576000357c0100000000000000000000000000000000000000000000000000000000900463ffffffff16806360fe47b114604e5780636d4ce63c14606e575b600080fd5b3415605857600080fd5b606c60048080359060200190919050506094565b005b3415607857600080fd5b607e609e565b6040518082815260200191505060405180910390f35b8060008190555050565b600080549050905600a165627a7a72305820122f55f799d70b5f6dbfd4312efb65cdbfaacddedf7c36249b8b1e915a8dd85b0029")
 			runtimeCode = "6060604052600436106049576000357c0100000000000000000000000000000000000000000000000000000000900463ffffffff16806360fe47b114604e5780636d4ce63c14606e575b600080fd5b3415605857600080fd5b606c60048080359060200190919050506094565b005b3415607857600080fd5b607e609e565b6040518082815260200191505060405180910390f35b8060008190555050565b600080549050905600a165627a7a72305820122f55f799d70b5f6dbfd4312efb65cdbfaacddedf7c36249b8b1e915a8dd85b0029"
+
+			// precompileProxyCode returns the deployment code of a contract
+			// which calls a precompile with its input and returns the output of
+			// the precompile, or reverts when the call fails
+			precompileProxyCode = func(precompile crypto.Address) []byte {
+				return []byte("6039600c60003960396000f3" +
+					"366000600037600060003660006000" + "73" + hex.EncodeToString(precompile.Bytes()) +
+					"5af13d600060003e610034573d6000fd5b3d6000f3")
+			}
+
+			// revertingProxyCode returns the deployment code of a contract which
+			// calls a precompile with its input and then reverts
+			revertingProxyCode = func(precompile crypto.Address) []byte {
+				return []byte("602c600c600039602c6000f3" +
+					"366000600037600060003660006000" + "73" + hex.EncodeToString(precompile.Bytes()) +
+					"5af15060006000fd")
+			}
+
+			// catchingProxyCode returns the deployment code of a contract which
+			// calls another contract with its input and succeeds whether the
+			// call failed or not
+			catchingProxyCode = func(callee crypto.Address) []byte {
+				return []byte("6027600c60003960276000f3" +
+					"366000600037600060003660006000" + "73" + hex.EncodeToString(callee.Bytes()) +
+					"5af100")
+			}
+
+			// abiString encodes a string of at most 32 bytes as the tail of a
+			// dynamic argument
+			abiString = func(s string) string {
+				return fmt.Sprintf("%064x", len(s)) + hex.EncodeToString(binary.RightPadBytes([]byte(s), 32))
+			}
+
+			// useNonces selects a fork which has nonces, so that transactions
+			// increment the nonce of their sender and contract addresses are
+			// derived from it
+			useNonces = func() {
+				fakeLedger[evm.ForkKey] = []byte("istanbul")
+			}
+
+			// creatorNonce returns the nonce of the creator in the ledger, which
+			// is incremented by the transactions modifying the EVM state
+			creatorNonce = func() uint64 {
+				addr, err := address.IdentityToAddr(creator)
+				Expect(err).ToNot(HaveOccurred())
+				acct, err := acm.Decode(fakeLedger[hex.EncodeToString(addr)])
+				Expect(err).ToNot(HaveOccurred())
+				return acct.Sequence
+			}
 		)
 
 		BeforeEach(func() {
@@ -124,7 +417,7 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 			res := evmcc.Invoke(stub)
 			Expect(res.Status).To(Equal(int32(shim.OK)))
 
-			// PutState Call is for setting the code for the contract account
+			// PutState Calls are for setting the code for the contract account
 			Expect(stub.PutStateCallCount()).To(Equal(1))
 
 			value := fakeLedger[string(res.Payload)]
@@ -153,7 +446,7 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 				res := evmcc.Invoke(stub)
 				Expect(res.Status).To(Equal(int32(shim.OK)))
 
-				// PutState Call is for setting the code for the contract account
+				// PutState Calls are for setting the code for the contract account
 				Expect(stub.PutStateCallCount()).To(Equal(1))
 
 				var err error
@@ -191,15 +484,91 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 
 		})
 
-		Context("when more than 2 args are given", func() {
+		Context("when a gas limit is given as the third arg", func() {
+			It("limits the gas available to the transaction", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode, []byte("10")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to deploy code"))
+				Expect(res.Message).To(ContainSubstring("insufficient gas"))
+			})
+
+			Context("when the contract reads the remaining gas", func() {
+				/*
+					Hand assembled contract which returns the gas available at its first instruction
+					GAS PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+				*/
+				var contractAddress crypto.Address
+
+				BeforeEach(func() {
+					stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte("6009600c60003960096000f35a60005260206000f3")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+
+					var err error
+					contractAddress, err = crypto.AddressFromHexString(string(res.Payload))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("runs the contract with the requested gas", func() {
+					stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000"), []byte("500")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+					gasLeft := binary.Uint64FromWord256(binary.LeftPadWord256(res.Payload))
+					Expect(gasLeft).To(Equal(uint64(500)))
+				})
+
+				It("reports the gas used by the transaction in the response message", func() {
+					stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000"), []byte("500")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+
+					gasUsed, err := strconv.ParseUint(res.Message, 10, 64)
+					Expect(err).ToNot(HaveOccurred())
+					// gas charged by burrow for the instructions of the contract
+					Expect(gasUsed).To(Equal(uint64(8)))
+				})
+
+				It("caps the requested gas at the gas limit of the chaincode", func() {
+					stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000"), []byte("1000000")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+					gasLeft := binary.Uint64FromWord256(binary.LeftPadWord256(res.Payload))
+					Expect(gasLeft).To(Equal(evm.DefaultGasLimit))
+				})
+			})
+
+			It("returns an error when the gas is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode, []byte("lots")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("invalid gas"))
+			})
+
+			Context("when a higher gas limit has been set at Init", func() {
+				BeforeEach(func() {
+					stub.GetArgsReturns([][]byte{[]byte("gaslimit=100000"), []byte("blockcounter=false")})
+					res := evmcc.Init(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+				})
+
+				It("allows the transaction to use more gas", func() {
+					stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode, []byte("50000")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+				})
+			})
+		})
+
+		Context("when more than 4 args are given", func() {
 			BeforeEach(func() {
-				stub.GetArgsReturns([][]byte{[]byte("arg1"), []byte("arg2"), []byte("arg3")})
+				stub.GetArgsReturns([][]byte{[]byte("arg1"), []byte("arg2"), []byte("arg3"), []byte("arg4"), []byte("arg5")})
 			})
 
 			It("returns an error", func() {
 				res := evmcc.Invoke(stub)
 				Expect(res.Status).To(Equal(int32(shim.ERROR)))
-				Expect(res.Message).To(ContainSubstring("expects 2 args"))
+				Expect(res.Message).To(ContainSubstring("expects between 2 and 4 args"))
 			})
 		})
 
@@ -233,7 +602,7 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 					It("returns an error", func() {
 						res := evmcc.Invoke(stub)
 						Expect(res.Status).To(Equal(int32(shim.ERROR)))
-						Expect(res.Message).To(ContainSubstring("expects 2 args"))
+						Expect(res.Message).To(ContainSubstring("expects between 2 and 4 args"))
 					})
 				})
 			})
@@ -246,7 +615,7 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 				It("returns an error", func() {
 					res := evmcc.Invoke(stub)
 					Expect(res.Status).To(Equal(int32(shim.ERROR)))
-					Expect(res.Message).To(ContainSubstring("expects 2 args"))
+					Expect(res.Message).To(ContainSubstring("expects between 2 and 4 args"))
 				})
 			})
 		})
@@ -259,7 +628,7 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 					res := evmcc.Invoke(stub)
 					Expect(res.Status).To(Equal(int32(shim.OK)))
 
-					// PutState Call is for setting the code for the contract account
+					// PutState Calls are for setting the code for the contract account
 					Expect(stub.PutStateCallCount()).To(Equal(1))
 
 					contractAddress, err := crypto.AddressFromHexString(string(res.Payload))
@@ -303,6 +672,139 @@ AiEA0GxTPOXVHo0gJpMbHc9B73TL5ZfDhujoDyjb8DToWPQ=
 			})
 		})
 
+		Context("when getStorageAt is the first arg provided", func() {
+			var contractAddress string
+
+			BeforeEach(func() {
+				// deploys SimpleStorage and stores 0x2a through set(uint256)
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("60fe47b1000000000000000000000000000000000000000000000000000000000000002a")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("returns the word stored in the slot", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getStorageAt"), []byte(contractAddress), []byte("0")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(string(res.Payload)).To(Equal("000000000000000000000000000000000000000000000000000000000000002a"))
+
+				stub.GetArgsReturns([][]byte{[]byte("getStorageAt"), []byte(contractAddress), []byte("0000000000000000000000000000000000000000000000000000000000000000")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(string(res.Payload)).To(Equal("000000000000000000000000000000000000000000000000000000000000002a"))
+			})
+
+			It("returns zero for slots which were never written", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getStorageAt"), []byte(contractAddress), []byte("1")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(string(res.Payload)).To(Equal("0000000000000000000000000000000000000000000000000000000000000000"))
+
+				stub.GetArgsReturns([][]byte{[]byte("getStorageAt"), []byte("0000000000000000000000000000000000000001"), []byte("0")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(string(res.Payload)).To(Equal("0000000000000000000000000000000000000000000000000000000000000000"))
+			})
+
+			It("returns an error when the slot is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getStorageAt"), []byte(contractAddress), []byte("slot")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to decode storage slot from slot"))
+
+				stub.GetArgsReturns([][]byte{[]byte("getStorageAt"), []byte(contractAddress), []byte("01" + strings.Repeat("00", 32))})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("is longer than 32 bytes"))
+			})
+
+			It("returns an error when the address is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getStorageAt"), []byte("malformed-address"), []byte("0")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to decode address"))
+			})
+		})
+
+		Context("when getAccount is the first arg provided", func() {
+			var (
+				contractAddress string
+				senderAddress   string
+			)
+
+			getAccount := func(addr string) map[string]interface{} {
+				stub.GetArgsReturns([][]byte{[]byte("getAccount"), []byte(addr)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				var info map[string]interface{}
+				Expect(json.Unmarshal(res.Payload, &info)).To(Succeed())
+				return info
+			}
+
+			BeforeEach(func() {
+				useNonces()
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+
+				addr, err := address.IdentityToAddr(creator)
+				Expect(err).ToNot(HaveOccurred())
+				senderAddress = hex.EncodeToString(addr)
+			})
+
+			It("describes the account of a contract", func() {
+				code, err := hex.DecodeString(runtimeCode)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getAccount(contractAddress)).To(Equal(map[string]interface{}{
+					"address":     contractAddress,
+					"exists":      true,
+					"balance":     float64(0),
+					"sequence":    float64(1),
+					"codeSize":    float64(len(code)),
+					"codeHash":    hex.EncodeToString(sha3.Sha3(code)),
+					"permissions": []interface{}{"send", "call", "createContract"},
+				}))
+			})
+
+			It("describes the account of a user", func() {
+				Expect(getAccount(senderAddress)).To(Equal(map[string]interface{}{
+					"address":     senderAddress,
+					"exists":      true,
+					"balance":     float64(0),
+					"sequence":    float64(1),
+					"codeSize":    float64(0),
+					"codeHash":    hex.EncodeToString(sha3.Sha3(nil)),
+					"permissions": []interface{}{},
+				}))
+			})
+
+			It("only returns the address of accounts which do not exist", func() {
+				Expect(getAccount("0000000000000000000000000000000000000001")).To(Equal(map[string]interface{}{
+					"address":     "0000000000000000000000000000000000000001",
+					"exists":      false,
+					"balance":     float64(0),
+					"sequence":    float64(0),
+					"codeSize":    float64(0),
+					"permissions": []interface{}{},
+				}))
+			})
+
+			It("returns an error when the address is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getAccount"), []byte("malformed-address")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to decode address"))
+			})
+		})
+
 		Describe("Voting DApp", func() {
 			var (
 				/* Voting App from https://solidity.readthedocs.io/en/develop/solidity-by-example.html#voting
@@ -760,6 +1262,30 @@ Vc4foA7mruwjI8sEng==
 					})
 				})
 
+				Context("if an event name and a structured payload have been set at Init", func() {
+					BeforeEach(func() {
+						stub.GetArgsReturns([][]byte{[]byte("eventname=evm:{address}:{topic0}"), []byte("eventpayload=structured"), []byte("blockcounter=false")})
+						res := evmcc.Init(stub)
+						Expect(res.Status).To(Equal(int32(shim.OK)))
+					})
+
+					It("names the chaincode event after the contract and the signature of the event", func() {
+						stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(SET + "53616d0000000000000000000000000000000000000000000000000000000000" + "0000000000000000000000000000000000000000000000000000000000000019" + "0000000000000000000000000000000000000000000000000000000000007530")})
+
+						initialEventCallCount := stub.SetEventCallCount()
+						res := evmcc.Invoke(stub)
+						Expect(res.Status).To(Equal(int32(shim.OK)))
+
+						Expect(stub.SetEventCallCount()).To(Equal(initialEventCallCount + 1))
+						setEventName, setEventPayload := stub.SetEventArgsForCall(initialEventCallCount)
+						Expect(setEventName).To(Equal("evm:" + strings.ToLower(contractAddress.String()) + ":e920a6ca2d94687457e136223552305dbabca6f28cf9c65d18efc2193a2369b0"))
+
+						var payload event.Payload
+						Expect(json.Unmarshal(setEventPayload, &payload)).To(Succeed())
+						Expect(payload).To(Equal(event.Payload{Logs: messagePayloads, Calls: []event.CallEvent{}, Structured: true}))
+					})
+				})
+
 				Context("if the method called does not emit any events", func() {
 					It("doesn't set any chaincode event", func() {
 						stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(GET)})
@@ -772,75 +1298,2356 @@ Vc4foA7mruwjI8sEng==
 			})
 		})
 
-		Context("when a smart contract creates other smart contracts", func() {
-			/*
-				pragma solidity ^0.5.0;
-					contract SimpleStorage {
-							uint storedData;
+		Context("when a contract calls other contracts", func() {
+			var (
+				// stores 0x2a at slot 0, loads it back and returns it
+				storeDeployCode = []byte("6010600c60003960106000f3602a60005560005460005260206000f3")
+				storeAddress    crypto.Address
+			)
+
+			deploy := func(deployCode []byte) crypto.Address {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				contractAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+				return contractAddress
+			}
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("eventpayload=calldata"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+
+				storeAddress = deploy(storeDeployCode)
+				Expect(stub.SetEventCallCount()).To(Equal(0))
+			})
+
+			It("sets a chaincode event with the calls", func() {
+				proxyAddress := deploy(precompileProxyCode(storeAddress))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress.String()), []byte("")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(stub.SetEventCallCount()).To(Equal(1))
+				_, setEventPayload := stub.SetEventArgsForCall(0)
+
+				var payload event.Payload
+				Expect(json.Unmarshal(setEventPayload, &payload)).To(Succeed())
+				Expect(payload.Logs).To(BeEmpty())
+				Expect(payload.Calls).To(Equal([]event.CallEvent{{
+					CallType: "call",
+					Caller:   strings.ToLower(proxyAddress.String()),
+					Callee:   strings.ToLower(storeAddress.String()),
+					Output:   fmt.Sprintf("%064x", 0x2a),
+					Depth:    1,
+				}}))
+			})
+
+			It("leaves out the input and output unless call data is recorded", func() {
+				stub.GetArgsReturns([][]byte{[]byte("eventpayload=calls"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				proxyAddress := deploy(precompileProxyCode(storeAddress))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress.String()), []byte("")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(stub.SetEventCallCount()).To(Equal(1))
+				_, setEventPayload := stub.SetEventArgsForCall(0)
+
+				var payload event.Payload
+				Expect(json.Unmarshal(setEventPayload, &payload)).To(Succeed())
+				Expect(payload.Calls).To(HaveLen(1))
+				Expect(payload.Calls[0].Input).To(BeEmpty())
+				Expect(payload.Calls[0].Output).To(BeEmpty())
+			})
+
+			It("does not record the calls of precompiles", func() {
+				proxyAddress := deploy(precompileProxyCode(evm.TxContextPrecompileAddress))
+
+				selector := hex.EncodeToString(sha3.Sha3([]byte("channelID()"))[:4])
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress.String()), []byte(selector)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+
+				Expect(stub.SetEventCallCount()).To(Equal(0))
+			})
+
+			It("does not set an event for the calls unless the payload records them", func() {
+				stub.GetArgsReturns([][]byte{[]byte("eventpayload=logs"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				proxyAddress := deploy(precompileProxyCode(storeAddress))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress.String()), []byte("")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(stub.SetEventCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a smart contract reverts", func() {
+			/*
+				Hand assembled contract which reverts with its input as the revert data
+				CALLDATASIZE PUSH1 0x00 PUSH1 0x00 CALLDATACOPY CALLDATASIZE PUSH1 0x00 REVERT
+			*/
+			var contractAddress crypto.Address
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte("600a600c600039600a6000f3366000600037366000fd")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				var err error
+				contractAddress, err = crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the reason given to Error(string) and the revert data", func() {
+				// Error("nope")
+				revertData := "08c379a0" +
+					"0000000000000000000000000000000000000000000000000000000000000020" +
+					"0000000000000000000000000000000000000000000000000000000000000004" +
+					"6e6f706500000000000000000000000000000000000000000000000000000000"
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(revertData)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(res.Message).To(Equal("execution reverted: nope"))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal(revertData))
+			})
+
+			It("returns the reason of a Panic(uint256) and the revert data", func() {
+				// Panic(0x11)
+				revertData := "4e487b71" +
+					"0000000000000000000000000000000000000000000000000000000000000011"
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(revertData)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(res.Message).To(Equal("execution reverted: panic: arithmetic underflow or overflow (0x11)"))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal(revertData))
+			})
+
+			It("returns the revert data when no reason can be decoded", func() {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("deadbeef")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(res.Message).To(Equal("execution reverted"))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal("deadbeef"))
+			})
+
+			It("returns the revert data of a reverted deployment", func() {
+				// The init code is the contract itself, which is its own input during deployment
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte("366000600037366000fd")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(res.Message).To(Equal("execution reverted"))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal("366000600037366000fd"))
+			})
+		})
+
+		Context("when native balances are used", func() {
+			var callerAddress, otherAddress crypto.Address
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("admin=TestOrg"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				addr, err := address.IdentityToAddr(creator)
+				Expect(err).ToNot(HaveOccurred())
+				callerAddress, err = crypto.AddressFromBytes(addr)
+				Expect(err).ToNot(HaveOccurred())
+				otherAddress, err = crypto.AddressFromHexString("1234567812345678123456781234567812345678")
+				Expect(err).ToNot(HaveOccurred())
+
+				stub.GetArgsReturns([][]byte{[]byte("mint"), []byte(callerAddress.String()), []byte("100")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal([]byte("100")))
+			})
+
+			getBalance := func(addr crypto.Address) string {
+				stub.GetArgsReturns([][]byte{[]byte("getBalance"), []byte(addr.String())})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				return string(res.Payload)
+			}
+
+			It("returns the balance of an account", func() {
+				Expect(getBalance(callerAddress)).To(Equal("100"))
+			})
+
+			It("returns a zero balance for accounts which do not exist", func() {
+				Expect(getBalance(otherAddress)).To(Equal("0"))
+			})
+
+			It("allows the admin to burn balance", func() {
+				stub.GetArgsReturns([][]byte{[]byte("burn"), []byte(callerAddress.String()), []byte("30")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal([]byte("70")))
+				Expect(getBalance(callerAddress)).To(Equal("70"))
+			})
+
+			It("does not burn more than the balance of the account", func() {
+				stub.GetArgsReturns([][]byte{[]byte("burn"), []byte(callerAddress.String()), []byte("101")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("insufficient funds"))
+				Expect(getBalance(callerAddress)).To(Equal("100"))
+			})
+
+			It("returns an error when the amount is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte("mint"), []byte(callerAddress.String()), []byte("lots")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to parse amount"))
+			})
+
+			It("transfers value to accounts which do not exist yet", func() {
+				stub.GetArgsReturns([][]byte{[]byte(otherAddress.String()), []byte(""), []byte(""), []byte("40")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(getBalance(callerAddress)).To(Equal("60"))
+				Expect(getBalance(otherAddress)).To(Equal("40"))
+			})
+
+			It("transfers value to contracts when they are deployed", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte("600a600c600039600a6000f3366000600037366000fd"), []byte(""), []byte("25")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				contractAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(getBalance(callerAddress)).To(Equal("75"))
+				Expect(getBalance(contractAddress)).To(Equal("25"))
+			})
+
+			It("does not transfer more than the balance of the caller", func() {
+				stub.GetArgsReturns([][]byte{[]byte(otherAddress.String()), []byte(""), []byte(""), []byte("101")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to execute contract"))
+				Expect(getBalance(callerAddress)).To(Equal("100"))
+				Expect(getBalance(otherAddress)).To(Equal("0"))
+			})
+
+			It("returns an error when the value is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte(otherAddress.String()), []byte(""), []byte(""), []byte("lots")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("invalid value"))
+			})
+
+			Context("when the creator is not a member of the admin MSP", func() {
+				BeforeEach(func() {
+					stub.GetCreatorReturns(marshalCreator("OtherOrg", []byte(user0Cert)), nil)
+				})
+
+				It("does not allow minting or burning", func() {
+					stub.GetArgsReturns([][]byte{[]byte("mint"), []byte(callerAddress.String()), []byte("100")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("unauthorized: OtherOrg is not the admin MSP"))
+
+					stub.GetArgsReturns([][]byte{[]byte("burn"), []byte(callerAddress.String()), []byte("100")})
+					res = evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("unauthorized: OtherOrg is not the admin MSP"))
+
+					Expect(getBalance(callerAddress)).To(Equal("100"))
+				})
+			})
+
+			Context("when no admin has been set", func() {
+				BeforeEach(func() {
+					delete(fakeLedger, evm.AdminKey)
+				})
+
+				It("does not allow minting", func() {
+					stub.GetArgsReturns([][]byte{[]byte("mint"), []byte(callerAddress.String()), []byte("100")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("no admin has been set at Init"))
+				})
+			})
+		})
+
+		Context("when the nonce of the sender is used", func() {
+			var callerAddress crypto.Address
+
+			BeforeEach(func() {
+				useNonces()
+				addr, err := address.IdentityToAddr(creator)
+				Expect(err).ToNot(HaveOccurred())
+				callerAddress, err = crypto.AddressFromBytes(addr)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			getNonce := func(addr crypto.Address) string {
+				stub.GetArgsReturns([][]byte{[]byte("getNonce"), []byte(addr.String())})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				return string(res.Payload)
+			}
+
+			It("derives contract addresses from the sender and its nonce as Ethereum does", func() {
+				sender, err := crypto.AddressFromHexString("6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(evm.NewContractAddress(sender, 0).String()).To(Equal("CD234A471B72BA2F1CCF0A70FCABA648A5EECD8D"))
+				Expect(evm.NewContractAddress(sender, 1).String()).To(Equal("343C43A37D37DFF08AE8C4A11544C718ABB4FCF8"))
+			})
+
+			It("returns a zero nonce for accounts which do not exist", func() {
+				Expect(getNonce(callerAddress)).To(Equal("0"))
+			})
+
+			It("deploys contracts at the address derived from the nonce of the sender", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(crypto.AddressFromHexString(string(res.Payload))).To(Equal(evm.NewContractAddress(callerAddress, 0)))
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(crypto.AddressFromHexString(string(res.Payload))).To(Equal(evm.NewContractAddress(callerAddress, 1)))
+			})
+
+			It("increments the nonce for transactions that modify state only", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(getNonce(callerAddress)).To(Equal("1"))
+
+				contractAddress := string(res.Payload)
+
+				// get() does not modify state
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("6d4ce63c")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(getNonce(callerAddress)).To(Equal("1"))
+
+				// set(42) modifies state
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("60fe47b1000000000000000000000000000000000000000000000000000000000000002a")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(getNonce(callerAddress)).To(Equal("2"))
+			})
+
+			It("derives the addresses of contracts created by contracts from the nonce of their creator", func() {
+				// creates an empty contract and returns its address
+				factoryDeployCode := "600f600c600039600f6000f3" + "600060006000f060005260206000f3"
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(factoryDeployCode)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				factoryAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(getNonce(factoryAddress)).To(Equal("1"))
+
+				for nonce := uint64(1); nonce <= 2; nonce++ {
+					stub.GetArgsReturns([][]byte{[]byte(factoryAddress.String()), []byte("")})
+					res = evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+					createdAddress, err := crypto.AddressFromBytes(res.Payload[12:])
+					Expect(err).ToNot(HaveOccurred())
+					Expect(createdAddress).To(Equal(evm.NewContractAddress(factoryAddress, nonce)))
+					Expect(getNonce(createdAddress)).To(Equal("1"))
+				}
+
+				Expect(getNonce(factoryAddress)).To(Equal("3"))
+			})
+
+			It("keeps the balance of the sender when incrementing the nonce", func() {
+				stub.GetArgsReturns([][]byte{[]byte("admin=TestOrg"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetArgsReturns([][]byte{[]byte("mint"), []byte(callerAddress.String()), []byte("100")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetArgsReturns([][]byte{[]byte("1234567812345678123456781234567812345678"), []byte(""), []byte(""), []byte("40")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetArgsReturns([][]byte{[]byte("getBalance"), []byte(callerAddress.String())})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(string(res.Payload)).To(Equal("60"))
+				Expect(getNonce(callerAddress)).To(Equal("1"))
+			})
+
+			It("returns an error when the queried address is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getNonce"), []byte("not-an-address")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to decode address"))
+			})
+
+			Context("when the fork has no nonces", func() {
+				BeforeEach(func() {
+					delete(fakeLedger, evm.ForkKey)
+				})
+
+				It("deploys contracts at the address derived from the transaction ID without incrementing the nonce", func() {
+					for _, txID := range []string{"tx1", "tx2"} {
+						stub.GetTxIDStub = func() string { return txID }
+						stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+						res := evmcc.Invoke(stub)
+						Expect(res.Status).To(Equal(int32(shim.OK)))
+
+						contractAddress, err := crypto.AddressFromHexString(string(res.Payload))
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contractAddress).To(Equal(crypto.NewContractAddress(callerAddress, crypto.Nonce(callerAddress, []byte(txID)))))
+						Expect(getNonce(contractAddress)).To(Equal("0"))
+					}
+
+					Expect(getNonce(callerAddress)).To(Equal("0"))
+					Expect(fakeLedger).ToNot(HaveKey(strings.ToLower(callerAddress.String())))
+				})
+
+				It("deploys the contracts of a batch at distinct addresses", func() {
+					stub.GetTxIDStub = func() string { return "tx1" }
+					calls := []evm.BatchCall{
+						{To: crypto.ZeroAddress.String(), Input: string(deployCode)},
+						{To: crypto.ZeroAddress.String(), Input: string(deployCode)},
+					}
+					callsBytes, err := json.Marshal(calls)
+					Expect(err).ToNot(HaveOccurred())
+					stub.GetArgsReturns([][]byte{[]byte("batch"), callsBytes})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+
+					var results []evm.BatchResult
+					Expect(json.Unmarshal(res.Payload, &results)).To(Succeed())
+					Expect(results).To(HaveLen(2))
+					Expect(results[0].ContractAddress).To(Equal(strings.ToLower(crypto.NewContractAddress(callerAddress, crypto.Nonce(callerAddress, []byte("tx1"))).String())))
+					Expect(results[1].ContractAddress).ToNot(Equal(results[0].ContractAddress))
+					Expect(getNonce(callerAddress)).To(Equal("0"))
+				})
+
+				It("does not increment the nonce of contracts which create contracts", func() {
+					// creates an empty contract and returns its address
+					factoryDeployCode := "600f600c600039600f6000f3" + "600060006000f060005260206000f3"
+					stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(factoryDeployCode)})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+					factoryAddress, err := crypto.AddressFromHexString(string(res.Payload))
+					Expect(err).ToNot(HaveOccurred())
+
+					var createdAddresses []crypto.Address
+					for i := 0; i < 2; i++ {
+						stub.GetArgsReturns([][]byte{[]byte(factoryAddress.String()), []byte("")})
+						res = evmcc.Invoke(stub)
+						Expect(res.Status).To(Equal(int32(shim.OK)))
+						createdAddress, err := crypto.AddressFromBytes(res.Payload[12:])
+						Expect(err).ToNot(HaveOccurred())
+						Expect(createdAddress).ToNot(Equal(evm.NewContractAddress(factoryAddress, 0)))
+						createdAddresses = append(createdAddresses, createdAddress)
+					}
+
+					Expect(createdAddresses[1]).ToNot(Equal(createdAddresses[0]))
+					Expect(getNonce(factoryAddress)).To(Equal("0"))
+				})
+			})
+		})
+
+		Context("when calls are batched", func() {
+			var (
+				callerAddress crypto.Address
+				SET           = "60fe47b1"
+				GET           = "6d4ce63c"
+			)
+
+			batch := func(calls ...evm.BatchCall) pb.Response {
+				callsBytes, err := json.Marshal(calls)
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte("batch"), callsBytes})
+				return evmcc.Invoke(stub)
+			}
+
+			getNonce := func() string {
+				stub.GetArgsReturns([][]byte{[]byte("getNonce"), []byte(callerAddress.String())})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				return string(res.Payload)
+			}
+
+			BeforeEach(func() {
+				useNonces()
+				addr, err := address.IdentityToAddr(creator)
+				Expect(err).ToNot(HaveOccurred())
+				callerAddress, err = crypto.AddressFromBytes(addr)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("runs the calls in order over the same state and returns their results", func() {
+				firstAddress := strings.ToLower(evm.NewContractAddress(callerAddress, 0).String())
+				secondAddress := strings.ToLower(evm.NewContractAddress(callerAddress, 1).String())
+
+				res := batch(
+					evm.BatchCall{To: crypto.ZeroAddress.String(), Input: string(deployCode)},
+					evm.BatchCall{To: crypto.ZeroAddress.String(), Input: string(deployCode)},
+					evm.BatchCall{To: firstAddress, Input: SET + "000000000000000000000000000000000000000000000000000000000000002a"},
+					evm.BatchCall{To: firstAddress, Input: GET, Gas: "100000"},
+				)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				var results []evm.BatchResult
+				Expect(json.Unmarshal(res.Payload, &results)).To(Succeed())
+				Expect(results).To(HaveLen(4))
+				Expect(results[0].ContractAddress).To(Equal(firstAddress))
+				Expect(results[1].ContractAddress).To(Equal(secondAddress))
+				Expect(results[2].Output).To(BeEmpty())
+				Expect(results[3].Output).To(Equal("000000000000000000000000000000000000000000000000000000000000002a"))
+
+				var totalGasUsed uint64
+				for _, result := range results {
+					Expect(result.GasUsed).ToNot(BeZero())
+					totalGasUsed += result.GasUsed
+				}
+				Expect(res.Message).To(Equal(strconv.FormatUint(totalGasUsed, 10)))
+
+				Expect(fakeLedger).To(HaveKey(firstAddress))
+				Expect(fakeLedger).To(HaveKey(secondAddress))
+				Expect(getNonce()).To(Equal("4"))
+			})
+
+			It("does not increment the nonce when no call modifies state", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress := string(res.Payload)
+
+				res = batch(evm.BatchCall{To: contractAddress, Input: GET}, evm.BatchCall{To: contractAddress, Input: GET})
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(getNonce()).To(Equal("1"))
+			})
+
+			It("commits none of the calls when one of them fails", func() {
+				contractAddress := strings.ToLower(evm.NewContractAddress(callerAddress, 0).String())
+
+				res := batch(
+					evm.BatchCall{To: crypto.ZeroAddress.String(), Input: string(deployCode)},
+					// the fallback function of SimpleStorage reverts
+					evm.BatchCall{To: contractAddress, Input: "deadbeef"},
+				)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(res.Message).To(Equal("batch call 1 failed: execution reverted"))
+
+				Expect(stub.PutStateCallCount()).To(Equal(0))
+				Expect(fakeLedger).ToNot(HaveKey(contractAddress))
+			})
+
+			It("returns an error when a call is malformed", func() {
+				res := batch(evm.BatchCall{To: crypto.ZeroAddress.String(), Input: string(deployCode)}, evm.BatchCall{To: "not-an-address", Input: GET})
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("batch call 1 failed: failed to decode address"))
+
+				res = batch(evm.BatchCall{To: crypto.ZeroAddress.String(), Input: "not-hex"})
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("batch call 0 failed: failed to decode input bytes"))
+
+				res = batch(evm.BatchCall{To: crypto.ZeroAddress.String(), Input: string(deployCode), Gas: "lots"})
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("batch call 0 failed: invalid gas"))
+
+				Expect(stub.PutStateCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when the batch is empty or malformed", func() {
+				res := batch()
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("batch has no calls"))
+
+				stub.GetArgsReturns([][]byte{[]byte("batch"), []byte("not json")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("failed to unmarshal batch"))
+			})
+		})
+
+		Context("when calls are made with multicall", func() {
+			var (
+				contractAddress string
+				putStateCount   int
+			)
+
+			multicall := func(calls ...evm.BatchCall) []evm.MulticallResult {
+				callsBytes, err := json.Marshal(calls)
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte("multicall"), callsBytes})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				var results []evm.MulticallResult
+				Expect(json.Unmarshal(res.Payload, &results)).To(Succeed())
+				return results
+			}
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("60fe47b1000000000000000000000000000000000000000000000000000000000000002a")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				putStateCount = stub.PutStateCallCount()
+			})
+
+			It("returns the outputs of all of the calls without writing state", func() {
+				results := multicall(
+					evm.BatchCall{To: contractAddress, Input: "6d4ce63c"},
+					evm.BatchCall{To: contractAddress, Input: "60fe47b1000000000000000000000000000000000000000000000000000000000000002b"},
+					evm.BatchCall{To: contractAddress, Input: "6d4ce63c", Gas: "100000"},
+				)
+				Expect(results).To(Equal([]evm.MulticallResult{
+					{Output: "000000000000000000000000000000000000000000000000000000000000002a"},
+					{},
+					// every call runs on the latest state, not on the writes of the previous calls
+					{Output: "000000000000000000000000000000000000000000000000000000000000002a"},
+				}))
+				Expect(stub.PutStateCallCount()).To(Equal(putStateCount))
+				Expect(stub.SetEventCallCount()).To(Equal(0))
+			})
+
+			It("returns the errors of failed calls along with the other results", func() {
+				results := multicall(
+					// the fallback function of SimpleStorage reverts
+					evm.BatchCall{To: contractAddress, Input: "deadbeef"},
+					evm.BatchCall{To: "not-an-address"},
+					evm.BatchCall{To: crypto.ZeroAddress.String(), Input: string(deployCode)},
+					evm.BatchCall{To: contractAddress, Input: "6d4ce63c"},
+				)
+				Expect(results).To(HaveLen(4))
+				Expect(results[0]).To(Equal(evm.MulticallResult{Error: "execution reverted", Reverted: true}))
+				Expect(results[1].Error).To(HavePrefix("failed to decode address"))
+				Expect(results[2]).To(Equal(evm.MulticallResult{Error: "deployments are not supported by multicall"}))
+				Expect(results[3]).To(Equal(evm.MulticallResult{Output: "000000000000000000000000000000000000000000000000000000000000002a"}))
+			})
+
+			It("returns an error when the calls are malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte("multicall"), []byte("not json")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("failed to unmarshal calls"))
+			})
+		})
+
+		Context("when a call is traced", func() {
+			var (
+				// stores 0x2a at slot 0, loads it back and returns it
+				storeDeployCode = []byte("6010600c60003960106000f3602a60005560005460005260206000f3")
+				contractAddress string
+				putStateCount   int
+			)
+
+			trace := func(call evm.BatchCall) evm.TraceResult {
+				callBytes, err := json.Marshal(call)
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte("trace"), callBytes})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+
+				var result evm.TraceResult
+				Expect(json.Unmarshal(res.Payload, &result)).To(Succeed())
+				return result
+			}
+
+			ops := func(logs []evm.StructLog) []string {
+				var ops []string
+				for _, log := range logs {
+					ops = append(ops, log.Op)
+				}
+				return ops
+			}
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), storeDeployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+				putStateCount = stub.PutStateCallCount()
+			})
+
+			It("returns the steps of the execution without writing state", func() {
+				result := trace(evm.BatchCall{To: contractAddress})
+				Expect(result.Failed).To(BeFalse())
+				Expect(result.Error).To(BeEmpty())
+				Expect(result.ReturnValue).To(Equal(fmt.Sprintf("%064x", 0x2a)))
+				Expect(ops(result.StructLogs)).To(Equal([]string{
+					"PUSH1", "PUSH1", "SSTORE", "PUSH1", "SLOAD", "PUSH1", "MSTORE", "PUSH1", "PUSH1", "RETURN",
+				}))
+
+				var pcs []uint64
+				var gasUsed uint64
+				for _, log := range result.StructLogs {
+					Expect(log.Depth).To(Equal(uint64(1)))
+					pcs = append(pcs, log.PC)
+					gasUsed += log.GasCost
+				}
+				Expect(pcs).To(Equal([]uint64{0, 2, 4, 5, 7, 8, 10, 11, 13, 15}))
+				Expect(result.Gas).ToNot(BeZero())
+				Expect(gasUsed).To(Equal(result.Gas))
+
+				zero := fmt.Sprintf("%064x", 0)
+				value := fmt.Sprintf("%064x", 0x2a)
+
+				sstore := result.StructLogs[2]
+				Expect(sstore.Stack).To(Equal([]string{value, zero}))
+				Expect(sstore.Memory).To(BeEmpty())
+				Expect(sstore.Storage).To(Equal(map[string]string{zero: value}))
+
+				sload := result.StructLogs[4]
+				Expect(sload.Stack).To(Equal([]string{zero}))
+				Expect(sload.Storage).To(Equal(map[string]string{zero: value}))
+
+				ret := result.StructLogs[9]
+				Expect(ret.Stack).To(Equal([]string{fmt.Sprintf("%064x", 0x20), zero}))
+				Expect(ret.Memory).To(Equal([]string{value}))
+				Expect(ret.Storage).To(BeNil())
+
+				Expect(stub.PutStateCallCount()).To(Equal(putStateCount))
+				Expect(stub.SetEventCallCount()).To(Equal(0))
+			})
+
+			It("returns the steps of calls to other contracts at their depth", func() {
+				callee, err := crypto.AddressFromHexString(contractAddress)
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), precompileProxyCode(callee)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				proxyAddress := string(res.Payload)
+
+				result := trace(evm.BatchCall{To: proxyAddress})
+				Expect(result.Failed).To(BeFalse())
+				Expect(result.ReturnValue).To(Equal(fmt.Sprintf("%064x", 0x2a)))
+
+				var depths []uint64
+				for _, log := range result.StructLogs {
+					if len(depths) == 0 || depths[len(depths)-1] != log.Depth {
+						depths = append(depths, log.Depth)
+					}
+				}
+				Expect(depths).To(Equal([]uint64{1, 2, 1}))
+			})
+
+			It("returns the steps of a deployment", func() {
+				result := trace(evm.BatchCall{To: crypto.ZeroAddress.String(), Input: string(storeDeployCode)})
+				Expect(result.Failed).To(BeFalse())
+				Expect(ops(result.StructLogs)).To(Equal([]string{
+					"PUSH1", "PUSH1", "PUSH1", "CODECOPY", "PUSH1", "PUSH1", "RETURN",
+				}))
+				Expect(stub.PutStateCallCount()).To(Equal(putStateCount))
+			})
+
+			It("returns the error and the revert data of a failed call", func() {
+				// the fallback function of SimpleStorage reverts
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				result := trace(evm.BatchCall{To: string(res.Payload), Input: "deadbeef"})
+				Expect(result.Failed).To(BeTrue())
+				Expect(result.Error).To(Equal("execution reverted"))
+				Expect(result.ReturnValue).To(BeEmpty())
+				Expect(result.StructLogs[len(result.StructLogs)-1].Op).To(Equal("REVERT"))
+			})
+
+			It("executes the call on behalf of the given creator", func() {
+				// returns the address of its caller
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte("6009600c60003960096000f3" + "3360005260206000f3")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				callerContract := string(res.Payload)
+				putStateCount = stub.PutStateCallCount()
+
+				callBytes, err := json.Marshal(evm.BatchCall{To: callerContract})
+				Expect(err).ToNot(HaveOccurred())
+				otherCreator := marshalCreator("OtherOrg", []byte(user1Cert))
+				stub.GetArgsReturns([][]byte{[]byte("trace"), callBytes, otherCreator})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+
+				var result evm.TraceResult
+				Expect(json.Unmarshal(res.Payload, &result)).To(Succeed())
+				otherAddress, err := address.IdentityToAddr(otherCreator)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ReturnValue).To(Equal(hex.EncodeToString(binary.LeftPadBytes(otherAddress, 32))))
+				Expect(stub.PutStateCallCount()).To(Equal(putStateCount))
+			})
+
+			It("returns an error when the call is malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte("trace"), []byte("not json")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("failed to unmarshal call"))
+
+				callBytes, err := json.Marshal(evm.BatchCall{To: "not-an-address"})
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte("trace"), callBytes})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("failed to decode address"))
+			})
+		})
+
+		Context("when post-Byzantium opcodes are used", func() {
+			var (
+				chainIDDeployCode     = []byte("6009600c60003960096000f34660005260206000f3")
+				selfBalanceDeployCode = []byte("6009600c60003960096000f34760005260206000f3")
+				// CREATE2 with a salt of 0x2a and an init code of a single STOP
+				create2DeployCode = []byte("6011600c60003960116000f3602a600160006000f560005260206000f3")
+				// SAR of -16 by 2
+				sarDeployCode = []byte("602c600c600039602c6000f37ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff060021d60005260206000f3")
+				// EXTCODEHASH of the contract itself
+				extCodeHashDeployCode = []byte("600a600c600039600a6000f3303f60005260206000f3")
+			)
+
+			deploy := func(deployCode []byte) crypto.Address {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				contractAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+				return contractAddress
+			}
+
+			call := func(contractAddress crypto.Address) []byte {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				return res.Payload
+			}
+
+			It("supports the shift opcodes and EXTCODEHASH in every fork", func() {
+				Expect(hex.EncodeToString(call(deploy(sarDeployCode)))).To(Equal("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffc"))
+
+				runtimeCode, err := hex.DecodeString("303f60005260206000f3")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(call(deploy(extCodeHashDeployCode))).To(Equal(sha3.Sha3(runtimeCode)))
+			})
+
+			Context("when no fork has been set at Init", func() {
+				It("does not support CHAINID, SELFBALANCE or CREATE2 at 0xf5", func() {
+					for _, deployCode := range [][]byte{chainIDDeployCode, selfBalanceDeployCode, create2DeployCode} {
+						contractAddress := deploy(deployCode)
+
+						stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("")})
+						res := evmcc.Invoke(stub)
+						Expect(res.Status).To(Equal(int32(shim.ERROR)))
+						Expect(res.Message).To(ContainSubstring("unknown opcode"))
+					}
+				})
+			})
+
+			Context("when the constantinople fork has been set at Init", func() {
+				BeforeEach(func() {
+					stub.GetArgsReturns([][]byte{[]byte("fork=constantinople"), []byte("blockcounter=false")})
+					res := evmcc.Init(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+				})
+
+				It("creates contracts with CREATE2 at the address specified by EIP-1014", func() {
+					contractAddress := deploy(create2DeployCode)
+					res := call(contractAddress)
+
+					salt := binary.LeftPadBytes([]byte{0x2a}, 32)
+					preimage := append([]byte{0xff}, contractAddress.Bytes()...)
+					preimage = append(preimage, salt...)
+					preimage = append(preimage, sha3.Sha3([]byte{0x00})...)
+					Expect(res[12:]).To(Equal(sha3.Sha3(preimage)[12:]))
+
+					_, ok := fakeLedger[hex.EncodeToString(res[12:])]
+					Expect(ok).To(BeTrue(), "the created contract account should be stored")
+				})
+
+				It("does not support CREATE2 at 0xfb", func() {
+					contractAddress := deploy([]byte("6011600c60003960116000f3602a600160006000fb60005260206000f3"))
+
+					stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("unknown opcode"))
+				})
+
+				It("does not support CHAINID", func() {
+					contractAddress := deploy(chainIDDeployCode)
+
+					stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("unknown opcode"))
+				})
+			})
+
+			Context("when the istanbul fork has been set at Init", func() {
+				BeforeEach(func() {
+					stub.GetArgsReturns([][]byte{[]byte("fork=istanbul"), []byte("chainid=1337"), []byte("admin=TestOrg"), []byte("blockcounter=false")})
+					res := evmcc.Init(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+				})
+
+				It("returns the chain ID set at Init from CHAINID", func() {
+					res := call(deploy(chainIDDeployCode))
+					Expect(new(big.Int).SetBytes(res).Uint64()).To(Equal(uint64(1337)))
+				})
+
+				It("returns the balance of the contract from SELFBALANCE", func() {
+					contractAddress := deploy(selfBalanceDeployCode)
+
+					stub.GetArgsReturns([][]byte{[]byte("mint"), []byte(contractAddress.String()), []byte("42")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+
+					Expect(new(big.Int).SetBytes(call(contractAddress)).Uint64()).To(Equal(uint64(42)))
+				})
+
+				It("creates contracts with CREATE2", func() {
+					res := call(deploy(create2DeployCode))
+					Expect(res[:12]).To(Equal(make([]byte, 12)))
+					Expect(res[12:]).ToNot(Equal(make([]byte, 20)))
+				})
+			})
+		})
+
+		Context("when a smart contract self-destructs", func() {
+			var (
+				// The constructor stores 0x2a in slot 1 and 0x2b in slot 2, the
+				// runtime code self-destructs sending its balance to the caller
+				selfDestructDeployCode = []byte("602a600155602b6002556002601660003960026000f333ff")
+				contractAddress        string
+				otherContractAddress   string
+			)
+
+			storageKeys := func(addr string) []string {
+				var keys []string
+				for key := range fakeLedger {
+					if strings.HasPrefix(key, addr) && key != addr {
+						keys = append(keys, key)
+					}
+				}
+				return keys
+			}
+
+			BeforeEach(func() {
+				useNonces()
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), selfDestructDeployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), selfDestructDeployCode})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				otherContractAddress = string(res.Payload)
+
+				Expect(fakeLedger).To(HaveKey(contractAddress))
+				Expect(storageKeys(contractAddress)).To(HaveLen(2))
+			})
+
+			It("removes the account and all of its storage", func() {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(fakeLedger).ToNot(HaveKey(contractAddress))
+				Expect(storageKeys(contractAddress)).To(BeEmpty())
+
+				stub.GetArgsReturns([][]byte{[]byte("getCode"), []byte(contractAddress)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(BeEmpty())
+			})
+
+			It("does not remove the storage of other contracts", func() {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(fakeLedger).To(HaveKey(otherContractAddress))
+				Expect(storageKeys(otherContractAddress)).To(HaveLen(2))
+			})
+		})
+
+		Context("when deployment is restricted", func() {
+			deploy := func() pb.Response {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				return evmcc.Invoke(stub)
+			}
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("admin=TestOrg"), []byte("deployers=DeployerOrg,evm.deployer=true"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("does not allow identities outside of the policy to deploy contracts", func() {
+				res := deploy()
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("unauthorized: identity of TestOrg is not allowed to deploy contracts"))
+				Expect(stub.PutStateCallCount()).To(Equal(3), "only the Init settings should have been written")
+			})
+
+			It("allows members of the deployer MSPs to deploy contracts", func() {
+				stub.GetCreatorReturns(marshalCreator("DeployerOrg", []byte(user0Cert)), nil)
+				res := deploy()
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("allows identities with a deployer attribute to deploy contracts", func() {
+				stub.GetCreatorReturns(marshalCreator("TestOrg", []byte(user1Cert)), nil)
+				res := deploy()
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("does not allow identities outside of the policy to create contracts from other contracts", func() {
+				// PUSH1 0x00 PUSH1 0x00 PUSH1 0x00 CREATE PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+				factoryDeployCode := "600f600c600039600f6000f3" + "600060006000f060005260206000f3"
+
+				stub.GetCreatorReturns(marshalCreator("DeployerOrg", []byte(user0Cert)), nil)
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(factoryDeployCode)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				factoryAddress := res.Payload
+
+				stub.GetCreatorReturns(creator, nil)
+				stub.GetArgsReturns([][]byte{factoryAddress, []byte("")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HaveSuffix("permission denied: identity of TestOrg is not allowed to deploy contracts"))
+
+				stub.GetCreatorReturns(marshalCreator("DeployerOrg", []byte(user0Cert)), nil)
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				Expect(res.Payload).ToNot(Equal(binary.Zero256.Bytes()))
+			})
+
+			It("allows anyone to call deployed contracts", func() {
+				stub.GetCreatorReturns(marshalCreator("DeployerOrg", []byte(user0Cert)), nil)
+				res := deploy()
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetCreatorReturns(creator, nil)
+				stub.GetArgsReturns([][]byte{res.Payload, []byte("60fe47b1000000000000000000000000000000000000000000000000000000000000002a")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("returns the deployers policy", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getDeployers")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(MatchJSON(`{"mspids":["DeployerOrg"],"attributes":{"evm.deployer":"true"}}`))
+			})
+
+			Context("when the admin changes the deployers", func() {
+				It("applies the new policy", func() {
+					stub.GetArgsReturns([][]byte{[]byte("setDeployers"), []byte("TestOrg")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+					Expect(res.Payload).To(MatchJSON(`{"mspids":["TestOrg"]}`))
+
+					res = deploy()
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+
+					stub.GetCreatorReturns(marshalCreator("OtherOrg", []byte(user1Cert)), nil)
+					res = deploy()
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("is not allowed to deploy contracts"))
+				})
+
+				It("allows everyone to deploy when the list is empty", func() {
+					stub.GetArgsReturns([][]byte{[]byte("setDeployers"), []byte("")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+					Expect(res.Payload).To(MatchJSON(`{}`))
+					Expect(fakeLedger).ToNot(HaveKey(evm.DeployersKey))
+
+					res = deploy()
+					Expect(res.Status).To(Equal(int32(shim.OK)))
+				})
+			})
+
+			Context("when a non admin changes the deployers", func() {
+				BeforeEach(func() {
+					stub.GetCreatorReturns(marshalCreator("DeployerOrg", []byte(user0Cert)), nil)
+				})
+
+				It("returns an error", func() {
+					stub.GetArgsReturns([][]byte{[]byte("setDeployers"), []byte("DeployerOrg,OtherOrg")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(Equal("unauthorized: DeployerOrg is not the admin MSP"))
+					Expect(fakeLedger[evm.DeployersKey]).To(MatchJSON(`{"mspids":["DeployerOrg"],"attributes":{"evm.deployer":"true"}}`))
+				})
+			})
+		})
+
+		Context("when a contract has an ACL", func() {
+			var contractAddress string
+
+			call := func() pb.Response {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("6d4ce63c")})
+				return evmcc.Invoke(stub)
+			}
+
+			setACL := func(acl string) pb.Response {
+				stub.GetCreatorReturns(creator, nil)
+				stub.GetArgsReturns([][]byte{[]byte("setACL"), []byte(contractAddress), []byte(acl)})
+				return evmcc.Invoke(stub)
+			}
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("admin=TestOrg"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+
+				res = setACL("ProtectedOrg,ou:evm,hf.EnrollmentID=auditor")
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(MatchJSON(`{"mspids":["ProtectedOrg"],"ous":["evm"],"attributes":{"hf.EnrollmentID":"auditor"}}`))
+			})
+
+			It("does not allow identities outside of the ACL to call the contract", func() {
+				stub.GetCreatorReturns(creator, nil)
+				res := call()
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal(fmt.Sprintf("unauthorized: identity of TestOrg is not allowed to call contract %s", contractAddress)))
+			})
+
+			It("does not allow identities outside of the ACL to call the contract through another contract", func() {
+				addr, err := crypto.AddressFromHexString(contractAddress)
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), precompileProxyCode(addr)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				proxyAddress := string(res.Payload)
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte("6d4ce63c")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HaveSuffix("permission denied: identity of TestOrg is not allowed to call contract " + contractAddress))
+
+				stub.GetCreatorReturns(marshalCreator("ProtectedOrg", []byte(user0Cert)), nil)
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				Expect(res.Payload).To(HaveLen(32))
+			})
+
+			It("allows members of the MSPs in the ACL to call the contract", func() {
+				stub.GetCreatorReturns(marshalCreator("ProtectedOrg", []byte(user0Cert)), nil)
+				res := call()
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("allows identities with an organizational unit in the ACL to call the contract", func() {
+				stub.GetCreatorReturns(marshalCreator("OtherOrg", []byte(user1Cert)), nil)
+				res := call()
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("allows identities with an attribute in the ACL to call the contract", func() {
+				res := setACL("hf.EnrollmentID=user1")
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetCreatorReturns(marshalCreator("OtherOrg", []byte(user1Cert)), nil)
+				res = call()
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetCreatorReturns(marshalCreator("OtherOrg", []byte(user0Cert)), nil)
+				res = call()
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("is not allowed to call contract"))
+			})
+
+			It("returns the ACL of the contract", func() {
+				stub.GetArgsReturns([][]byte{[]byte("getACL"), []byte(contractAddress)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(MatchJSON(`{"mspids":["ProtectedOrg"],"ous":["evm"],"attributes":{"hf.EnrollmentID":"auditor"}}`))
+			})
+
+			It("allows everyone to call the contract once the ACL is emptied", func() {
+				res := setACL("")
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger).ToNot(HaveKey(evm.ACLKeyPrefix + contractAddress))
+
+				res = call()
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("does not restrict other contracts", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetArgsReturns([][]byte{res.Payload, []byte("6d4ce63c")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			Context("when a non admin changes the ACL", func() {
+				It("returns an error", func() {
+					stub.GetCreatorReturns(marshalCreator("ProtectedOrg", []byte(user0Cert)), nil)
+					stub.GetArgsReturns([][]byte{[]byte("setACL"), []byte(contractAddress), []byte("")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(Equal("unauthorized: ProtectedOrg is not the admin MSP"))
+				})
+			})
+
+			Context("when the ACL has an empty organizational unit", func() {
+				It("returns an error", func() {
+					res := setACL("ou:")
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("organizational unit must not be empty"))
+				})
+			})
+		})
+
+		Context("when the admin operates on a deployed contract", func() {
+			var (
+				contractAddress string
+				SET             = "60fe47b1"
+				GET             = "6d4ce63c"
+				// upgradedRuntimeCode returns twice the value in storage slot 0
+				upgradedRuntimeCode = "60005460020260005260206000f3"
+			)
+
+			invoke := func(args ...string) pb.Response {
+				var byteArgs [][]byte
+				for _, arg := range args {
+					byteArgs = append(byteArgs, []byte(arg))
+				}
+				stub.GetArgsReturns(byteArgs)
+				return evmcc.Invoke(stub)
+			}
+
+			auditEvent := func(i int) (string, map[string]string) {
+				name, payload := stub.SetEventArgsForCall(i)
+				var e map[string]string
+				Expect(json.Unmarshal(payload, &e)).To(Succeed())
+				return name, e
+			}
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("admin=TestOrg"), []byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				res = invoke(crypto.ZeroAddress.String(), string(deployCode))
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+
+				res = invoke(contractAddress, SET+"0000000000000000000000000000000000000000000000000000000000000015")
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("rejects transactions modifying a paused contract and allows queries", func() {
+				res := invoke("pause", contractAddress)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger).To(HaveKey(evm.PausedKeyPrefix + contractAddress))
+
+				Expect(stub.SetEventCallCount()).To(Equal(1))
+				name, e := auditEvent(0)
+				Expect(name).To(Equal("evmcc:audit:pause"))
+				Expect(e).To(Equal(map[string]string{"Operation": "pause", "Address": contractAddress, "Admin": "TestOrg"}))
+
+				res = invoke(contractAddress, SET+"000000000000000000000000000000000000000000000000000000000000002a")
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal(fmt.Sprintf("contract %s is paused", contractAddress)))
+
+				res = invoke(contractAddress, GET)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal("0000000000000000000000000000000000000000000000000000000000000015"))
+			})
+
+			It("rejects writes to a paused contract made through another contract", func() {
+				addr, err := crypto.AddressFromHexString(contractAddress)
+				Expect(err).ToNot(HaveOccurred())
+				res := invoke(crypto.ZeroAddress.String(), string(precompileProxyCode(addr)))
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				proxyAddress := string(res.Payload)
+
+				res = invoke("pause", contractAddress)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				res = invoke(proxyAddress, SET+"000000000000000000000000000000000000000000000000000000000000002a")
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal(fmt.Sprintf("contract %s is paused", contractAddress)))
+
+				res = invoke(contractAddress, GET)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal("0000000000000000000000000000000000000000000000000000000000000015"))
+			})
+
+			It("rejects transactions in which a paused contract calls contracts that modify their state", func() {
+				// tokenDeployCode gives 1000 tokens to the deployer, its runtime
+				// code transfers the amount of its second argument from the
+				// caller to the address of its first one, as ERC20 transfer does
+				tokenDeployCode := "6103e833556013601160003960136000f3" + "602435803354033355600435805482019055" + "00"
+				res := invoke(crypto.ZeroAddress.String(), tokenDeployCode)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				tokenAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+
+				res = invoke(crypto.ZeroAddress.String(), string(catchingProxyCode(tokenAddress)))
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				walletAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+
+				res = invoke(crypto.ZeroAddress.String(), string(catchingProxyCode(walletAddress)))
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				outerAddress := string(res.Payload)
+
+				transfer := func(to crypto.Address, amount int) string {
+					return "a9059cbb" + hex.EncodeToString(binary.LeftPadBytes(to.Bytes(), 32)) + fmt.Sprintf("%064x", amount)
+				}
+				res = invoke(tokenAddress.String(), transfer(walletAddress, 100))
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				res = invoke("pause", walletAddress.String())
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				walletKey := hex.EncodeToString(binary.LeftPadBytes(walletAddress.Bytes(), 32))
+				tokenStorageKey := strings.ToLower(tokenAddress.String()) + walletKey
+				balance := fakeLedger[tokenStorageKey]
+				Expect(balance).ToNot(BeEmpty())
+
+				putStates := stub.PutStateCallCount()
+				res = invoke(walletAddress.String(), transfer(crypto.ZeroAddress, 10))
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal(fmt.Sprintf("contract %s is paused", strings.ToLower(walletAddress.String()))))
+
+				res = invoke(outerAddress, transfer(crypto.ZeroAddress, 10))
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal(fmt.Sprintf("contract %s is paused", strings.ToLower(walletAddress.String()))))
+
+				Expect(stub.PutStateCallCount()).To(Equal(putStates))
+				Expect(fakeLedger[tokenStorageKey]).To(Equal(balance))
+			})
+
+			It("rejects batches which modify state after a paused contract is queried", func() {
+				res := invoke(crypto.ZeroAddress.String(), string(deployCode))
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				otherAddress := string(res.Payload)
+
+				res = invoke("pause", contractAddress)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				calls, err := json.Marshal([]evm.BatchCall{
+					{To: contractAddress, Input: GET},
+					{To: otherAddress, Input: SET + "000000000000000000000000000000000000000000000000000000000000002a"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				res = invoke("batch", string(calls))
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal(fmt.Sprintf("contract %s is paused", contractAddress)))
+
+				calls, err = json.Marshal([]evm.BatchCall{{To: contractAddress, Input: GET}, {To: otherAddress, Input: GET}})
+				Expect(err).ToNot(HaveOccurred())
+				res = invoke("batch", string(calls))
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+			})
+
+			It("allows transactions modifying the contract once it is unpaused", func() {
+				res := invoke("pause", contractAddress)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				res = invoke("unpause", contractAddress)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger).ToNot(HaveKey(evm.PausedKeyPrefix + contractAddress))
+
+				Expect(stub.SetEventCallCount()).To(Equal(2))
+				name, e := auditEvent(1)
+				Expect(name).To(Equal("evmcc:audit:unpause"))
+				Expect(e).To(Equal(map[string]string{"Operation": "unpause", "Address": contractAddress, "Admin": "TestOrg"}))
+
+				res = invoke(contractAddress, SET+"000000000000000000000000000000000000000000000000000000000000002a")
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+			})
+
+			It("replaces the code of the contract and keeps its storage", func() {
+				res := invoke("upgrade", contractAddress, upgradedRuntimeCode)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				code, err := hex.DecodeString(upgradedRuntimeCode)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stub.SetEventCallCount()).To(Equal(1))
+				name, e := auditEvent(0)
+				Expect(name).To(Equal("evmcc:audit:upgrade"))
+				Expect(e).To(Equal(map[string]string{
+					"Operation": "upgrade",
+					"Address":   contractAddress,
+					"Admin":     "TestOrg",
+					"CodeHash":  hex.EncodeToString(sha3.Sha3(code)),
+				}))
+
+				res = invoke("getCode", contractAddress)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(string(res.Payload)).To(Equal(upgradedRuntimeCode))
+
+				res = invoke(contractAddress, "")
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal("000000000000000000000000000000000000000000000000000000000000002a"))
+			})
+
+			It("returns an error when the runtime code is malformed", func() {
+				res := invoke("upgrade", contractAddress, "not code")
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("failed to upgrade contract: failed to decode runtime code"))
+				Expect(stub.SetEventCallCount()).To(Equal(0))
+			})
+
+			It("returns an error when there is no contract at the address", func() {
+				for _, operation := range []string{"pause", "unpause"} {
+					res := invoke(operation, "1234567812345678123456781234567812345678")
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(Equal("no contract at 1234567812345678123456781234567812345678"))
+				}
+
+				res := invoke("upgrade", "1234567812345678123456781234567812345678", upgradedRuntimeCode)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("no contract at 1234567812345678123456781234567812345678"))
+				Expect(stub.SetEventCallCount()).To(Equal(0))
+			})
+
+			Context("when the creator is not a member of the admin MSP", func() {
+				BeforeEach(func() {
+					stub.GetCreatorReturns(marshalCreator("OtherOrg", []byte(user0Cert)), nil)
+				})
+
+				It("does not allow pausing, unpausing or upgrading contracts", func() {
+					for _, args := range [][]string{{"pause", contractAddress}, {"unpause", contractAddress}, {"upgrade", contractAddress, upgradedRuntimeCode}} {
+						res := invoke(args...)
+						Expect(res.Status).To(Equal(int32(shim.ERROR)))
+						Expect(res.Message).To(Equal("unauthorized: OtherOrg is not the admin MSP"))
+					}
+					Expect(stub.SetEventCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when a smart contract reads the block context", func() {
+			/*
+				Hand assembled contract which returns (block.number, block.timestamp)
+				NUMBER PUSH1 0x00 MSTORE TIMESTAMP PUSH1 0x20 MSTORE PUSH1 0x40 PUSH1 0x00 RETURN
+			*/
+			var (
+				deployCode      = "600d600c600039600d6000f3436000524260205260406000f3"
+				contractAddress crypto.Address
+				txTimestamp     = &timestamp.Timestamp{Seconds: 1546300800}
+			)
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("blockcounter=true")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetTxTimestampReturns(txTimestamp, nil)
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(deployCode)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.BlockHeightKey]).To(Equal([]byte("1")))
+
+				var err error
+				contractAddress, err = crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("executes the contract with the next block height and the proposal timestamp", func() {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal(
+					"0000000000000000000000000000000000000000000000000000000000000002" +
+						"000000000000000000000000000000000000000000000000000000005c2aad80"))
+			})
+
+			It("does not advance the block height for transactions that do not modify state", func() {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.BlockHeightKey]).To(Equal([]byte("1")))
+			})
+
+			It("advances the block height with every transaction that modifies state", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(deployCode)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.BlockHeightKey]).To(Equal([]byte("2")))
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload[:32]).To(Equal(binary.Int64ToWord256(3).Bytes()))
+			})
+
+			It("executes the contract at block height 0 and records no block when the block counter is disabled", func() {
+				stub.GetArgsReturns([][]byte{[]byte("blockcounter=false")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload[:32]).To(Equal(binary.Zero256.Bytes()))
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(deployCode)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(fakeLedger[evm.BlockHeightKey]).To(Equal([]byte("1")))
+				Expect(fakeLedger).ToNot(HaveKey(evm.BlockHashKey(2)))
+			})
+
+			Context("when the transaction timestamp cannot be retrieved", func() {
+				BeforeEach(func() {
+					stub.GetTxTimestampReturns(nil, errors.New("boom!"))
+				})
+
+				It("returns an error", func() {
+					stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte("00000000")})
+					res := evmcc.Invoke(stub)
+					Expect(res.Status).To(Equal(int32(shim.ERROR)))
+					Expect(res.Message).To(ContainSubstring("failed to get block context"))
+				})
+			})
+		})
+
+		Context("when a smart contract uses the BLOCKHASH opcode", func() {
+			/*
+				Hand assembled contract which returns blockhash(height) for the height given as input
+				PUSH1 0x00 CALLDATALOAD BLOCKHASH PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+			*/
+			var (
+				deployCode      = "600c600c600039600c6000f36000354060005260206000f3"
+				contractAddress crypto.Address
+			)
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte("blockcounter=true")})
+				res := evmcc.Init(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(deployCode)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				var err error
+				contractAddress, err = crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the hash recorded for a previous block", func() {
+				Expect(fakeLedger).To(HaveKey(evm.BlockHashKey(1)))
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(hex.EncodeToString(binary.Int64ToWord256(1).Bytes()))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal(fakeLedger[evm.BlockHashKey(1)]))
+			})
+
+			It("returns a zero hash for a block without a recorded hash", func() {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(hex.EncodeToString(binary.Int64ToWord256(0).Bytes()))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal(binary.Zero256.Bytes()))
+			})
+
+			It("returns a zero hash for the current and future blocks", func() {
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(hex.EncodeToString(binary.Int64ToWord256(2).Bytes()))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal(binary.Zero256.Bytes()))
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(hex.EncodeToString(binary.Int64ToWord256(1000).Bytes()))})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal(binary.Zero256.Bytes()))
+			})
+
+			It("returns a zero hash for blocks older than the most recent 256 blocks", func() {
+				fakeLedger[evm.BlockHeightKey] = []byte("300")
+				fakeLedger[evm.BlockHashKey(10)] = []byte("stale-hash")
+
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(hex.EncodeToString(binary.Int64ToWord256(10).Bytes()))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal(binary.Zero256.Bytes()))
+			})
+
+			It("only keeps the hashes of the most recent 256 blocks", func() {
+				fakeLedger[evm.BlockHeightKey] = []byte("300")
+				fakeLedger[evm.BlockHashKey(45)] = []byte("stale-hash")
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(deployCode)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(fakeLedger[evm.BlockHeightKey]).To(Equal([]byte("301")))
+				Expect(fakeLedger).To(HaveKey(evm.BlockHashKey(301)))
+				Expect(fakeLedger).ToNot(HaveKey(evm.BlockHashKey(45)))
+			})
+		})
+
+		Context("when a smart contract creates other smart contracts", func() {
+			/*
+				pragma solidity ^0.5.0;
+					contract SimpleStorage {
+							uint storedData;
 
 							function set(uint x) public {
 									storedData = x;
 							}
 
-							function get() public view returns (uint) {
-									return storedData;
-							}
-					}
+							function get() public view returns (uint) {
+									return storedData;
+							}
+					}
+
+					contract SimpleStorageCreator {
+							function createSimpleStorage() public returns (SimpleStorage simpleStorageAddress){
+									return new SimpleStorage();
+							}
+					}
+			*/
+			var (
+				deployCode          = "608060405234801561001057600080fd5b50610204806100206000396000f3fe60806040526004361061003b576000357c01000000000000000000000000000000000000000000000000000000009004806340d66b3b14610040575b600080fd5b34801561004c57600080fd5b50610055610097565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b60006100a16100c3565b604051809103906000f0801580156100bd573d6000803e3d6000fd5b50905090565b604051610105806100d48339019056fe608060405234801561001057600080fd5b5060e68061001f6000396000f3fe6080604052600436106043576000357c01000000000000000000000000000000000000000000000000000000009004806360fe47b11460485780636d4ce63c14607f575b600080fd5b348015605357600080fd5b50607d60048036036020811015606857600080fd5b810190808035906020019092919050505060a7565b005b348015608a57600080fd5b50609160b1565b6040518082815260200191505060405180910390f35b8060008190555050565b6000805490509056fea165627a7a723058204fba53eb31e370bbdbe1f1064d4e2a6aa794f3533a7563265edde21d4e8563b40029a165627a7a723058208317acddf85b105bfe01677c962f5e4493cfc1b65fcd91dfc3474d3bd4be2eeb0029"
+				runtimeCode         = "60806040526004361061003b576000357c01000000000000000000000000000000000000000000000000000000009004806340d66b3b14610040575b600080fd5b34801561004c57600080fd5b50610055610097565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b60006100a16100c3565b604051809103906000f0801580156100bd573d6000803e3d6000fd5b50905090565b604051610105806100d48339019056fe608060405234801561001057600080fd5b5060e68061001f6000396000f3fe6080604052600436106043576000357c01000000000000000000000000000000000000000000000000000000009004806360fe47b11460485780636d4ce63c14607f575b600080fd5b348015605357600080fd5b50607d60048036036020811015606857600080fd5b810190808035906020019092919050505060a7565b005b348015608a57600080fd5b50609160b1565b6040518082815260200191505060405180910390f35b8060008190555050565b6000805490509056fea165627a7a723058204fba53eb31e370bbdbe1f1064d4e2a6aa794f3533a7563265edde21d4e8563b40029a165627a7a723058208317acddf85b105bfe01677c962f5e4493cfc1b65fcd91dfc3474d3bd4be2eeb0029"
+				contractAddress     crypto.Address
+				CREATESIMPLESTORAGE = "40d66b3b"
+				SET                 = "60fe47b1"
+				GET                 = "6d4ce63c"
+			)
+
+			It("creates a new contract account", func() {
+				// Set contract creator
+				stub.GetCreatorReturns(creator, nil)
+
+				//Deploying SimpleStorageCreator
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(deployCode)})
+
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				//check that contract account has been created
+				value := fakeLedger[string(res.Payload)]
+				contractAcct, err := acm.Decode(value)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(hex.EncodeToString(contractAcct.Code.Bytes())).To(Equal(runtimeCode))
+
+				contractAddress, err = crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+
+				// Invoke createSimpleStorage()
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(CREATESIMPLESTORAGE)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				//Returned value has 12 bytes of leading zeros than 20 bytes which is the address
+				createdContractAddr, err := crypto.AddressFromHexString(hex.EncodeToString(res.Payload[len(res.Payload)-20:]))
+				Expect(err).ToNot(HaveOccurred())
+
+				//Invoke the SimpleStorage contract that was created previously
+				stub.GetArgsReturns([][]byte{[]byte(createdContractAddr.String()), []byte(SET + "000000000000000000000000000000000000000000000000000000000000002a")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				//Get the previously set value
+				stub.GetArgsReturns([][]byte{[]byte(createdContractAddr.String()), []byte(GET)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal("000000000000000000000000000000000000000000000000000000000000002a"))
+			})
+		})
+
+		Context("when a contract invokes another chaincode", func() {
+			var (
+				proxyAddress string
+				// invokeChaincode("asset", ["balanceOf", "alice"], "")
+				invokeInput = hex.EncodeToString(sha3.Sha3([]byte("invokeChaincode(string,bytes[],string)"))[:4]) +
+					"0000000000000000000000000000000000000000000000000000000000000060" +
+					"00000000000000000000000000000000000000000000000000000000000000a0" +
+					"0000000000000000000000000000000000000000000000000000000000000180" +
+					"0000000000000000000000000000000000000000000000000000000000000005" +
+					"6173736574000000000000000000000000000000000000000000000000000000" +
+					"0000000000000000000000000000000000000000000000000000000000000002" +
+					"0000000000000000000000000000000000000000000000000000000000000040" +
+					"0000000000000000000000000000000000000000000000000000000000000080" +
+					"0000000000000000000000000000000000000000000000000000000000000009" +
+					"62616c616e63654f660000000000000000000000000000000000000000000000" +
+					"0000000000000000000000000000000000000000000000000000000000000005" +
+					"616c696365000000000000000000000000000000000000000000000000000000" +
+					"0000000000000000000000000000000000000000000000000000000000000000"
+			)
+
+			BeforeEach(func() {
+				useNonces()
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), precompileProxyCode(evm.ChaincodePrecompileAddress)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				proxyAddress = string(res.Payload)
+			})
+
+			It("invokes the chaincode and returns the status, payload and message of its response", func() {
+				stub.InvokeChaincodeReturns(shim.Success([]byte("100")))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte(invokeInput)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal(
+					"00000000000000000000000000000000000000000000000000000000000000c8" +
+						"0000000000000000000000000000000000000000000000000000000000000060" +
+						"00000000000000000000000000000000000000000000000000000000000000a0" +
+						"0000000000000000000000000000000000000000000000000000000000000003" +
+						"3130300000000000000000000000000000000000000000000000000000000000" +
+						"0000000000000000000000000000000000000000000000000000000000000000"))
+
+				Expect(stub.InvokeChaincodeCallCount()).To(Equal(1))
+				name, args, channel := stub.InvokeChaincodeArgsForCall(0)
+				Expect(name).To(Equal("asset"))
+				Expect(args).To(Equal([][]byte{[]byte("balanceOf"), []byte("alice")}))
+				Expect(channel).To(BeEmpty())
+
+				// the transaction is recorded as the invoked chaincode may write
+				Expect(creatorNonce()).To(Equal(uint64(2)))
+			})
+
+			It("returns the error status and message of a failed response", func() {
+				stub.InvokeChaincodeReturns(shim.Error("boom"))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte(invokeInput)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal(
+					"00000000000000000000000000000000000000000000000000000000000001f4" +
+						"0000000000000000000000000000000000000000000000000000000000000060" +
+						"0000000000000000000000000000000000000000000000000000000000000080" +
+						"0000000000000000000000000000000000000000000000000000000000000000" +
+						"0000000000000000000000000000000000000000000000000000000000000004" +
+						"626f6f6d00000000000000000000000000000000000000000000000000000000"))
+			})
+
+			It("fails the call when the arguments are malformed", func() {
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte(invokeInput[:8+64])})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(stub.InvokeChaincodeCallCount()).To(Equal(0))
+			})
+
+			It("fails the call when the function is unknown", func() {
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte("deadbeef")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(stub.InvokeChaincodeCallCount()).To(Equal(0))
+			})
+
+			It("fails the transaction when the call which invoked the chaincode reverted", func() {
+				stub.InvokeChaincodeReturns(shim.Success([]byte("100")))
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), revertingProxyCode(evm.ChaincodePrecompileAddress)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				revertingAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), catchingProxyCode(revertingAddress)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				catchingAddress := string(res.Payload)
+
+				stub.GetArgsReturns([][]byte{[]byte(catchingAddress), []byte(invokeInput)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(ContainSubstring("a reverted call made a write outside of the EVM state which cannot be undone"))
+				Expect(stub.InvokeChaincodeCallCount()).To(Equal(1))
+			})
 
-					contract SimpleStorageCreator {
-							function createSimpleStorage() public returns (SimpleStorage simpleStorageAddress){
-									return new SimpleStorage();
-							}
-					}
-			*/
+			It("cannot be called by a paused contract", func() {
+				addr, err := crypto.AddressFromHexString(proxyAddress)
+				Expect(err).ToNot(HaveOccurred())
+				fakeLedger[evm.PausedKey(addr)] = []byte("true")
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte(invokeInput)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(stub.InvokeChaincodeCallCount()).To(Equal(0))
+			})
+
+			traceInvoke := func(extraArgs ...[]byte) evm.TraceResult {
+				callBytes, err := json.Marshal(evm.BatchCall{To: proxyAddress, Input: invokeInput})
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns(append([][]byte{[]byte("trace"), callBytes}, extraArgs...))
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+
+				var result evm.TraceResult
+				Expect(json.Unmarshal(res.Payload, &result)).To(Succeed())
+				return result
+			}
+
+			It("is invoked and listed by a trace on behalf of its signer", func() {
+				stub.InvokeChaincodeReturns(shim.Success([]byte("100")))
+
+				result := traceInvoke(creator)
+				Expect(result.Failed).To(BeFalse())
+				Expect(result.Writes).To(Equal([]string{`invoke chaincode asset on channel ""`}))
+				Expect(stub.InvokeChaincodeCallCount()).To(Equal(1))
+			})
+
+			It("is not invoked by a trace on behalf of another creator", func() {
+				stub.InvokeChaincodeReturns(shim.Success([]byte("100")))
+
+				result := traceInvoke(marshalCreator("OtherOrg", []byte(user1Cert)))
+				Expect(result.Failed).To(BeFalse())
+				// the status of the response is 500
+				Expect(result.ReturnValue).To(HavePrefix(fmt.Sprintf("%064x", 500)))
+				Expect(stub.InvokeChaincodeCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a contract reads the transaction context", func() {
+			var proxyAddress string
+
+			call := func(signature string) string {
+				selector := hex.EncodeToString(sha3.Sha3([]byte(signature))[:4])
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte(selector)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				return hex.EncodeToString(res.Payload)
+			}
+
+			BeforeEach(func() {
+				useNonces()
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), precompileProxyCode(evm.TxContextPrecompileAddress)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				proxyAddress = string(res.Payload)
+
+				stub.GetTxIDStub = func() string { return "mytx" }
+				stub.GetTxTimestampReturns(&timestamp.Timestamp{Seconds: 1570000000, Nanos: 42}, nil)
+				stub.GetChannelIDReturns("mychannel")
+			})
+
+			It("returns the transaction ID", func() {
+				Expect(call("txID()")).To(Equal(
+					"0000000000000000000000000000000000000000000000000000000000000020" +
+						"0000000000000000000000000000000000000000000000000000000000000004" +
+						"6d79747800000000000000000000000000000000000000000000000000000000"))
+			})
+
+			It("returns the proposal timestamp", func() {
+				Expect(call("txTimestamp()")).To(Equal(
+					"000000000000000000000000000000000000000000000000000000005d944c80" +
+						"000000000000000000000000000000000000000000000000000000000000002a"))
+			})
+
+			It("returns the channel ID", func() {
+				Expect(call("channelID()")).To(Equal(
+					"0000000000000000000000000000000000000000000000000000000000000020" +
+						"0000000000000000000000000000000000000000000000000000000000000009" +
+						"6d796368616e6e656c0000000000000000000000000000000000000000000000"))
+			})
+
+			It("returns the MSP ID of the creator", func() {
+				Expect(call("creatorMSPID()")).To(Equal(
+					"0000000000000000000000000000000000000000000000000000000000000020" +
+						"0000000000000000000000000000000000000000000000000000000000000007" +
+						"546573744f726700000000000000000000000000000000000000000000000000"))
+			})
+
+			It("does not record the transaction as modifying the EVM state", func() {
+				call("channelID()")
+				Expect(creatorNonce()).To(Equal(uint64(1)))
+			})
+		})
+
+		Context("when a contract checks the attributes of the caller", func() {
+			var proxyAddress string
+
+			call := func(signature string, args ...string) string {
+				selector := hex.EncodeToString(sha3.Sha3([]byte(signature))[:4])
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), []byte(selector + strings.Join(args, ""))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				return hex.EncodeToString(res.Payload)
+			}
+
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), precompileProxyCode(evm.ABACPrecompileAddress)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				proxyAddress = string(res.Payload)
+
+				stub.GetCreatorReturns(marshalCreator("Org1MSP", []byte(user1Cert)), nil)
+			})
+
+			It("returns whether the caller has an attribute with a value", func() {
+				Expect(call("hasAttribute(string,string)",
+					fmt.Sprintf("%064x", 0x40), fmt.Sprintf("%064x", 0x80), abiString("evm.deployer"), abiString("true"),
+				)).To(Equal(fmt.Sprintf("%064x", 1)))
+
+				Expect(call("hasAttribute(string,string)",
+					fmt.Sprintf("%064x", 0x40), fmt.Sprintf("%064x", 0x80), abiString("evm.deployer"), abiString("false"),
+				)).To(Equal(fmt.Sprintf("%064x", 0)))
+
+				Expect(call("hasAttribute(string,string)",
+					fmt.Sprintf("%064x", 0x40), fmt.Sprintf("%064x", 0x80), abiString("role"), abiString("auditor"),
+				)).To(Equal(fmt.Sprintf("%064x", 0)))
+			})
+
+			It("returns the value of an attribute of the caller", func() {
+				Expect(call("getAttribute(string)", fmt.Sprintf("%064x", 0x20), abiString("hf.EnrollmentID"))).To(Equal(
+					fmt.Sprintf("%064x", 0x40) + fmt.Sprintf("%064x", 1) + abiString("user1")))
+
+				Expect(call("getAttribute(string)", fmt.Sprintf("%064x", 0x20), abiString("role"))).To(Equal(
+					fmt.Sprintf("%064x", 0x40) + fmt.Sprintf("%064x", 0) + fmt.Sprintf("%064x", 0)))
+			})
+
+			It("returns the MSP ID of the caller", func() {
+				Expect(call("callerMSPID()")).To(Equal(fmt.Sprintf("%064x", 0x20) + abiString("Org1MSP")))
+			})
+
+			It("returns the organizational units of the caller", func() {
+				Expect(call("callerOUs()")).To(Equal(
+					fmt.Sprintf("%064x", 0x20) + fmt.Sprintf("%064x", 2) +
+						fmt.Sprintf("%064x", 0x40) + fmt.Sprintf("%064x", 0x80) + abiString("client") + abiString("evm")))
+			})
+
+			It("returns no organizational units when the certificate of the caller has none", func() {
+				stub.GetCreatorReturns(creator, nil)
+				Expect(call("callerOUs()")).To(Equal(fmt.Sprintf("%064x", 0x20) + fmt.Sprintf("%064x", 0)))
+			})
+		})
+
+		Context("when a contract uses private data", func() {
 			var (
-				deployCode          = "608060405234801561001057600080fd5b50610204806100206000396000f3fe60806040526004361061003b576000357c01000000000000000000000000000000000000000000000000000000009004806340d66b3b14610040575b600080fd5b34801561004c57600080fd5b50610055610097565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b60006100a16100c3565b604051809103906000f0801580156100bd573d6000803e3d6000fd5b50905090565b604051610105806100d48339019056fe608060405234801561001057600080fd5b5060e68061001f6000396000f3fe6080604052600436106043576000357c01000000000000000000000000000000000000000000000000000000009004806360fe47b11460485780636d4ce63c14607f575b600080fd5b348015605357600080fd5b50607d60048036036020811015606857600080fd5b810190808035906020019092919050505060a7565b005b348015608a57600080fd5b50609160b1565b6040518082815260200191505060405180910390f35b8060008190555050565b6000805490509056fea165627a7a723058204fba53eb31e370bbdbe1f1064d4e2a6aa794f3533a7563265edde21d4e8563b40029a165627a7a723058208317acddf85b105bfe01677c962f5e4493cfc1b65fcd91dfc3474d3bd4be2eeb0029"
-				runtimeCode         = "60806040526004361061003b576000357c01000000000000000000000000000000000000000000000000000000009004806340d66b3b14610040575b600080fd5b34801561004c57600080fd5b50610055610097565b604051808273ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200191505060405180910390f35b60006100a16100c3565b604051809103906000f0801580156100bd573d6000803e3d6000fd5b50905090565b604051610105806100d48339019056fe608060405234801561001057600080fd5b5060e68061001f6000396000f3fe6080604052600436106043576000357c01000000000000000000000000000000000000000000000000000000009004806360fe47b11460485780636d4ce63c14607f575b600080fd5b348015605357600080fd5b50607d60048036036020811015606857600080fd5b810190808035906020019092919050505060a7565b005b348015608a57600080fd5b50609160b1565b6040518082815260200191505060405180910390f35b8060008190555050565b6000805490509056fea165627a7a723058204fba53eb31e370bbdbe1f1064d4e2a6aa794f3533a7563265edde21d4e8563b40029a165627a7a723058208317acddf85b105bfe01677c962f5e4493cfc1b65fcd91dfc3474d3bd4be2eeb0029"
-				contractAddress     crypto.Address
-				CREATESIMPLESTORAGE = "40d66b3b"
-				SET                 = "60fe47b1"
-				GET                 = "6d4ce63c"
+				proxyAddress  string
+				proxyAcctAddr crypto.Address
 			)
 
-			It("creates a new contract account", func() {
-				// Set contract creator
-				stub.GetCreatorReturns(creator, nil)
+			// collectionKeyArgs are the arguments ("secrets", "salary")
+			collectionKeyArgs := fmt.Sprintf("%064x", 0x40) + fmt.Sprintf("%064x", 0x80) + abiString("secrets") + abiString("salary")
 
-				//Deploying SimpleStorageCreator
-				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), []byte(deployCode)})
+			input := func(signature string, args string) []byte {
+				return []byte(hex.EncodeToString(sha3.Sha3([]byte(signature))[:4]) + args)
+			}
 
+			BeforeEach(func() {
+				useNonces()
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), precompileProxyCode(evm.PrivateDataPrecompileAddress)})
 				res := evmcc.Invoke(stub)
 				Expect(res.Status).To(Equal(int32(shim.OK)))
+				proxyAddress = string(res.Payload)
 
-				//check that contract account has been created
-				value := fakeLedger[string(res.Payload)]
-				contractAcct, err := acm.Decode(value)
+				var err error
+				proxyAcctAddr, err = crypto.AddressFromHexString(proxyAddress)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(hex.EncodeToString(contractAcct.Code.Bytes())).To(Equal(runtimeCode))
+			})
 
-				contractAddress, err = crypto.AddressFromHexString(string(res.Payload))
+			It("reads private data under the key of the contract", func() {
+				stub.GetPrivateDataReturns([]byte("1000"), nil)
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("getPrivateData(string,string)", collectionKeyArgs)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal(fmt.Sprintf("%064x", 0x20) + abiString("1000")))
+
+				Expect(stub.GetPrivateDataCallCount()).To(Equal(1))
+				collection, key := stub.GetPrivateDataArgsForCall(0)
+				Expect(collection).To(Equal("secrets"))
+				Expect(key).To(Equal(proxyAddress + ":salary"))
+				Expect(key).To(Equal(evm.PrivateDataKey(proxyAcctAddr, "salary")))
+			})
+
+			It("writes and deletes private data under the key of the contract", func() {
+				args := fmt.Sprintf("%064x", 0x60) + fmt.Sprintf("%064x", 0xa0) + fmt.Sprintf("%064x", 0xe0) +
+					abiString("secrets") + abiString("salary") + abiString("2000")
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("putPrivateData(string,string,bytes)", args)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(stub.PutPrivateDataCallCount()).To(Equal(1))
+				collection, key, value := stub.PutPrivateDataArgsForCall(0)
+				Expect(collection).To(Equal("secrets"))
+				Expect(key).To(Equal(proxyAddress + ":salary"))
+				Expect(value).To(Equal([]byte("2000")))
+				Expect(creatorNonce()).To(Equal(uint64(2)))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("delPrivateData(string,string)", collectionKeyArgs)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(stub.DelPrivateDataCallCount()).To(Equal(1))
+				collection, key = stub.DelPrivateDataArgsForCall(0)
+				Expect(collection).To(Equal("secrets"))
+				Expect(key).To(Equal(proxyAddress + ":salary"))
+			})
+
+			It("does not write private data from a call which reverted", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), revertingProxyCode(evm.PrivateDataPrecompileAddress)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				revertingAddress, err := crypto.AddressFromHexString(string(res.Payload))
 				Expect(err).ToNot(HaveOccurred())
 
-				// Invoke createSimpleStorage()
-				stub.GetArgsReturns([][]byte{[]byte(contractAddress.String()), []byte(CREATESIMPLESTORAGE)})
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), catchingProxyCode(revertingAddress)})
 				res = evmcc.Invoke(stub)
 				Expect(res.Status).To(Equal(int32(shim.OK)))
+				catchingAddress := string(res.Payload)
 
-				//Returned value has 12 bytes of leading zeros than 20 bytes which is the address
-				createdContractAddr, err := crypto.AddressFromHexString(hex.EncodeToString(res.Payload[len(res.Payload)-20:]))
+				args := fmt.Sprintf("%064x", 0x60) + fmt.Sprintf("%064x", 0xa0) + fmt.Sprintf("%064x", 0xe0) +
+					abiString("secrets") + abiString("salary") + abiString("2000")
+				stub.GetArgsReturns([][]byte{[]byte(catchingAddress), input("putPrivateData(string,string,bytes)", args)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				Expect(stub.PutPrivateDataCallCount()).To(Equal(0))
+
+				stub.GetArgsReturns([][]byte{[]byte(catchingAddress), input("delPrivateData(string,string)", collectionKeyArgs)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				Expect(stub.DelPrivateDataCallCount()).To(Equal(0))
+			})
+
+			It("does not write private data for a paused contract", func() {
+				fakeLedger[evm.PausedKey(proxyAcctAddr)] = []byte("true")
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("delPrivateData(string,string)", collectionKeyArgs)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(stub.DelPrivateDataCallCount()).To(Equal(0))
+			})
+
+			It("runs the private data writes of multicall without writing private data", func() {
+				args := fmt.Sprintf("%064x", 0x60) + fmt.Sprintf("%064x", 0xa0) + fmt.Sprintf("%064x", 0xe0) +
+					abiString("secrets") + abiString("salary") + abiString("2000")
+				calls := []evm.BatchCall{{To: proxyAddress, Input: string(input("putPrivateData(string,string,bytes)", args))}}
+
+				callsBytes, err := json.Marshal(calls)
+				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte("multicall"), callsBytes})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				var results []evm.MulticallResult
+				Expect(json.Unmarshal(res.Payload, &results)).To(Succeed())
+				Expect(results).To(Equal([]evm.MulticallResult{{}}))
+				Expect(stub.PutPrivateDataCallCount()).To(Equal(0))
+			})
+
+			It("lists the private data writes of a trace without writing private data", func() {
+				args := fmt.Sprintf("%064x", 0x60) + fmt.Sprintf("%064x", 0xa0) + fmt.Sprintf("%064x", 0xe0) +
+					abiString("secrets") + abiString("salary") + abiString("2000")
+				call := evm.BatchCall{To: proxyAddress, Input: string(input("putPrivateData(string,string,bytes)", args))}
+
+				callBytes, err := json.Marshal(call)
 				Expect(err).ToNot(HaveOccurred())
+				stub.GetArgsReturns([][]byte{[]byte("trace"), callBytes})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				var result evm.TraceResult
+				Expect(json.Unmarshal(res.Payload, &result)).To(Succeed())
+				Expect(result.Failed).To(BeFalse(), result.Error)
+				Expect(result.Writes).To(Equal([]string{"put private data " + proxyAddress + ":salary in collection secrets"}))
+				Expect(stub.PutPrivateDataCallCount()).To(Equal(0))
+			})
+
+			It("fails the call when the private data cannot be read", func() {
+				stub.GetPrivateDataReturns(nil, errors.New("not a member of the collection"))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("getPrivateData(string,string)", collectionKeyArgs)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+			})
+
+			It("returns the hash of private data when the shim supports it", func() {
+				hash := sha3.Sha3([]byte("1000"))
+				hashStub := &privateDataHashStub{MockStub: stub, hash: hash}
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("getPrivateDataHash(string,string)", collectionKeyArgs)})
+				res := evmcc.Invoke(hashStub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(res.Payload).To(Equal(hash))
+				Expect(hashStub.collection).To(Equal("secrets"))
+				Expect(hashStub.key).To(Equal(proxyAddress + ":salary"))
 
-				//Invoke the SimpleStorage contract that was created previously
-				stub.GetArgsReturns([][]byte{[]byte(createdContractAddr.String()), []byte(SET + "000000000000000000000000000000000000000000000000000000000000002a")})
 				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+			})
+		})
+
+		Context("when the input is given in the transient map", func() {
+			var (
+				contractAddress string
+				SET             = "60fe47b1"
+				GET             = "6d4ce63c"
+			)
+
+			get := func() string {
+				stub.GetTransientReturns(nil, nil)
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte(GET)})
+				res := evmcc.Invoke(stub)
 				Expect(res.Status).To(Equal(int32(shim.OK)))
+				return hex.EncodeToString(res.Payload)
+			}
 
-				//Get the previously set value
-				stub.GetArgsReturns([][]byte{[]byte(createdContractAddr.String()), []byte(GET)})
+			BeforeEach(func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), deployCode})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				contractAddress = string(res.Payload)
+			})
+
+			It("reads the whole input from the transient map when the input argument is empty", func() {
+				stub.GetTransientReturns(map[string][]byte{
+					evm.TransientInputKey: []byte(SET + "000000000000000000000000000000000000000000000000000000000000002a"),
+				}, nil)
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte("")})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(get()).To(Equal("000000000000000000000000000000000000000000000000000000000000002a"))
+			})
+
+			It("replaces parts of the input with the parts in the transient map", func() {
+				stub.GetTransientReturns(map[string][]byte{
+					evm.TransientInputPartPrefix + "4": []byte("000000000000000000000000000000000000000000000000000000000000002b"),
+				}, nil)
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte(SET)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(get()).To(Equal("000000000000000000000000000000000000000000000000000000000000002b"))
+			})
+
+			It("fails when the input is given both as argument and in the transient map", func() {
+				stub.GetTransientReturns(map[string][]byte{evm.TransientInputKey: []byte(GET)}, nil)
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte(GET)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("input is given both as argument and in the transient map"))
+			})
+
+			It("fails when a part of the input is malformed", func() {
+				stub.GetTransientReturns(map[string][]byte{evm.TransientInputPartPrefix + "four": []byte("00")}, nil)
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte(GET)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("invalid offset of transient input part evmcc:input:four"))
+
+				stub.GetTransientReturns(map[string][]byte{evm.TransientInputPartPrefix + "4": []byte("zz")}, nil)
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(HavePrefix("failed to decode transient input part evmcc:input:4"))
+			})
+
+			It("fails when a part of the input starts past its end", func() {
+				stub.GetTransientReturns(map[string][]byte{evm.TransientInputPartPrefix + "9000000000000": []byte("00")}, nil)
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte(GET)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("invalid offset of transient input part evmcc:input:9000000000000"))
+
+				stub.GetTransientReturns(map[string][]byte{evm.TransientInputPartPrefix + "9223372036854775807": []byte("00")}, nil)
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("invalid offset of transient input part evmcc:input:9223372036854775807"))
+
+				stub.GetTransientReturns(map[string][]byte{evm.TransientInputPartPrefix + "4294967295": []byte("00")}, nil)
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("transient input part evmcc:input:4294967295 starts past the end of the input of 4 bytes"))
+
+				stub.GetTransientReturns(map[string][]byte{
+					evm.TransientInputPartPrefix + "4":  []byte("00"),
+					evm.TransientInputPartPrefix + "36": []byte("00"),
+				}, nil)
 				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("transient input part evmcc:input:36 starts past the end of the input of 5 bytes"))
+			})
+
+			It("fails when the transient map cannot be read", func() {
+				stub.GetTransientReturns(nil, errors.New("boom"))
+				stub.GetArgsReturns([][]byte{[]byte(contractAddress), []byte(GET)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.ERROR)))
+				Expect(res.Message).To(Equal("failed to get transient map: boom"))
+			})
+		})
+
+		Context("when a contract sets endorsement policies", func() {
+			var (
+				proxyAddress  string
+				proxyAcctAddr crypto.Address
+			)
+
+			input := func(signature string, args ...string) []byte {
+				return []byte(hex.EncodeToString(sha3.Sha3([]byte(signature))[:4]) + strings.Join(args, ""))
+			}
+
+			// stringArray encodes an array of strings of at most 32 bytes as
+			// the tail of a dynamic argument
+			stringArray := func(strs ...string) string {
+				encoded := fmt.Sprintf("%064x", len(strs))
+				for i := range strs {
+					encoded += fmt.Sprintf("%064x", 32*len(strs)+64*i)
+				}
+				for _, s := range strs {
+					encoded += abiString(s)
+				}
+				return encoded
+			}
+
+			expectPolicy := func(ep []byte, mspIDs ...string) {
+				envelope := &common.SignaturePolicyEnvelope{}
+				Expect(proto.Unmarshal(ep, envelope)).To(Succeed())
+				Expect(envelope.Rule.GetNOutOf().GetN()).To(Equal(int32(len(mspIDs))))
+				Expect(envelope.Rule.GetNOutOf().GetRules()).To(HaveLen(len(mspIDs)))
+				Expect(envelope.Identities).To(HaveLen(len(mspIDs)))
+				for i, mspID := range mspIDs {
+					Expect(envelope.Rule.GetNOutOf().GetRules()[i].GetSignedBy()).To(Equal(int32(i)))
+					Expect(envelope.Identities[i].PrincipalClassification).To(Equal(msp.MSPPrincipal_ROLE))
+
+					role := &msp.MSPRole{}
+					Expect(proto.Unmarshal(envelope.Identities[i].Principal, role)).To(Succeed())
+					Expect(role.MspIdentifier).To(Equal(mspID))
+					Expect(role.Role).To(Equal(msp.MSPRole_PEER))
+				}
+			}
+
+			BeforeEach(func() {
+				useNonces()
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), precompileProxyCode(evm.EndorsementPrecompileAddress)})
+				res := evmcc.Invoke(stub)
 				Expect(res.Status).To(Equal(int32(shim.OK)))
-				Expect(hex.EncodeToString(res.Payload)).To(Equal("000000000000000000000000000000000000000000000000000000000000002a"))
+				proxyAddress = string(res.Payload)
+
+				var err error
+				proxyAcctAddr, err = crypto.AddressFromHexString(proxyAddress)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("requires a peer of each organization to endorse writes of the account of the contract", func() {
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("setAccountEndorsers(string[])",
+					fmt.Sprintf("%064x", 0x20), stringArray("Org2MSP", "Org1MSP", "Org2MSP"))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(stub.SetStateValidationParameterCallCount()).To(Equal(1))
+				key, ep := stub.SetStateValidationParameterArgsForCall(0)
+				Expect(key).To(Equal(proxyAddress))
+				expectPolicy(ep, "Org1MSP", "Org2MSP")
+				Expect(creatorNonce()).To(Equal(uint64(2)))
+			})
+
+			It("does not set the policy from a call which reverted", func() {
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), revertingProxyCode(evm.EndorsementPrecompileAddress)})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				revertingAddress, err := crypto.AddressFromHexString(string(res.Payload))
+				Expect(err).ToNot(HaveOccurred())
+
+				stub.GetArgsReturns([][]byte{[]byte(crypto.ZeroAddress.String()), catchingProxyCode(revertingAddress)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				catchingAddress := string(res.Payload)
+
+				stub.GetArgsReturns([][]byte{[]byte(catchingAddress), input("setAccountEndorsers(string[])",
+					fmt.Sprintf("%064x", 0x20), stringArray("Org1MSP"))})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)), res.Message)
+				Expect(stub.SetStateValidationParameterCallCount()).To(Equal(0))
+			})
+
+			It("requires a peer of each organization to endorse writes of a storage slot of the contract", func() {
+				slot := fmt.Sprintf("%064x", 5)
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("setStorageEndorsers(bytes32,string[])",
+					slot, fmt.Sprintf("%064x", 0x40), stringArray("Org1MSP"))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(stub.SetStateValidationParameterCallCount()).To(Equal(1))
+				key, ep := stub.SetStateValidationParameterArgsForCall(0)
+				Expect(key).To(Equal(proxyAddress + slot))
+				expectPolicy(ep, "Org1MSP")
+			})
+
+			It("removes the policy when no organization is given", func() {
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("setAccountEndorsers(string[])",
+					fmt.Sprintf("%064x", 0x20), stringArray())})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				Expect(stub.SetStateValidationParameterCallCount()).To(Equal(1))
+				_, ep := stub.SetStateValidationParameterArgsForCall(0)
+				Expect(ep).To(BeNil())
+			})
+
+			It("sets and gets the validation parameters of the contract", func() {
+				slot := fmt.Sprintf("%064x", 5)
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("setStorageValidationParameter(bytes32,bytes)",
+					slot, fmt.Sprintf("%064x", 0x40), abiString("policy"))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+
+				key, ep := stub.SetStateValidationParameterArgsForCall(0)
+				Expect(key).To(Equal(proxyAddress + slot))
+				Expect(ep).To(Equal([]byte("policy")))
+
+				stub.GetStateValidationParameterReturns([]byte("policy"), nil)
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("getStorageValidationParameter(bytes32)", slot)})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(hex.EncodeToString(res.Payload)).To(Equal(fmt.Sprintf("%064x", 0x20) + abiString("policy")))
+				Expect(stub.GetStateValidationParameterArgsForCall(0)).To(Equal(proxyAddress + slot))
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("getAccountValidationParameter()")})
+				res = evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(shim.OK)))
+				Expect(stub.GetStateValidationParameterArgsForCall(1)).To(Equal(proxyAddress))
+			})
+
+			It("does not set policies for a paused contract", func() {
+				fakeLedger[evm.PausedKey(proxyAcctAddr)] = []byte("true")
+
+				stub.GetArgsReturns([][]byte{[]byte(proxyAddress), input("setAccountValidationParameter(bytes)",
+					fmt.Sprintf("%064x", 0x20), abiString("policy"))})
+				res := evmcc.Invoke(stub)
+				Expect(res.Status).To(Equal(int32(evm.RevertedStatus)))
+				Expect(stub.SetStateValidationParameterCallCount()).To(Equal(0))
 			})
 		})
 	})
 })
+
+// privateDataHashStub is a stub of a Fabric version whose shim returns the
+// hash of private data.
+type privateDataHashStub struct {
+	*evmcc_mocks.MockStub
+	hash            []byte
+	collection, key string
+}
+
+func (s *privateDataHashStub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	s.collection, s.key = collection, key
+	return s.hash, nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/fabric-chaincode-evm/eventmanager"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// executor runs the calls and deployments of a transaction on behalf of its
+// sender. They share one EVM state, so each of them sees the writes of the
+// previous ones and the writes of all of them are committed together. An
+// executor must be closed once the transaction has been executed.
+type executor struct {
+	stub      shim.ChaincodeStubInterface
+	params    evm.Params
+	caller    crypto.Address
+	state     *trackingState
+	evmCache  *evm.State
+	eventSink *eventmanager.EventManager
+	vm        *evm.VM
+	// nonces is set when the fork has nonces, nonce is then the nonce of the
+	// sender for the next call or deployment, each of them counts as a
+	// transaction of the sender
+	nonces bool
+	nonce  uint64
+	calls  uint64
+}
+
+// newExecutor returns an executor for the transaction of stub. Its EVM checks
+// that the sender may call each contract called by another contract and may
+// deploy each contract created by another contract, as call and deploy do for
+// the transaction, and keeps whether a called contract is paused. The given
+// options are applied to its EVM after the options of the fork set at Init.
+func newExecutor(stub shim.ChaincodeStubInterface, options ...func(*evm.VM)) (*executor, error) {
+	// get caller account from creator public key
+	callerAddr, err := getCallerAddress(stub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller address: %s", err)
+	}
+
+	params, err := newParams(stub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block context: %s", err)
+	}
+
+	vmOptions, nonces, err := getVMOptions(stub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get EVM options: %s", err)
+	}
+	state := &trackingState{StateManager: statemanager.NewStateManager(stub), paused: pausedChecker(stub)}
+	vmOptions = append(vmOptions, evm.CallChecker(func(calleeAddr crypto.Address) error {
+		if err := checkCaller(stub, calleeAddr); err != nil {
+			return err
+		}
+		return state.checkCall(calleeAddr)
+	}), evm.CreateChecker(func(crypto.Address) error {
+		return checkDeployer(stub)
+	}))
+	vmOptions = append(vmOptions, options...)
+
+	var senderNonce uint64
+	if nonces {
+		senderNonce, err = getNonce(state, callerAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nonce: %s", err)
+		}
+	}
+
+	eventSink, err := newEventManager(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	registerNativeContext(stub, state)
+
+	nonce := crypto.Nonce(callerAddr, []byte(stub.GetTxID()))
+	return &executor{
+		stub:      stub,
+		params:    params,
+		caller:    callerAddr,
+		state:     state,
+		evmCache:  evm.NewState(state, blockHashGetter(stub)),
+		eventSink: eventSink,
+		vm:        evm.NewVM(params, callerAddr, nonce, evmLogger, vmOptions...),
+		nonces:    nonces,
+		nonce:     senderNonce,
+	}, nil
+}
+
+// close releases the native context of the transaction.
+func (e *executor) close() {
+	unregisterNativeContext(e.state)
+}
+
+// deploy runs the deployment code of a contract and stores the runtime code it
+// returns in a new account. The payload of a successful response is the
+// address of the contract.
+func (e *executor) deploy(input []byte, value uint64, gas *uint64) pb.Response {
+	logger.Debugf("Deploy contract")
+
+	if err := checkDeployer(e.stub); err != nil {
+		return shim.Error(fmt.Sprintf("unauthorized: %s", err))
+	}
+
+	contractAddr := legacyContractAddress(e.caller, e.stub.GetTxID(), e.calls)
+	if e.nonces {
+		logger.Debugf("Contract nonce number = %d", e.nonce)
+		contractAddr = NewContractAddress(e.caller, e.nonce)
+	}
+	// Contract account needs to be created before setting code to it
+	e.evmCache.CreateAccount(contractAddr)
+	if evmErr := e.evmCache.Error(); evmErr != nil {
+		return shim.Error(fmt.Sprintf("failed to create the contract account: %s ", evmErr))
+	}
+
+	// Contracts start with nonce 1 as in Ethereum
+	if e.nonces {
+		e.evmCache.IncSequence(contractAddr)
+		if evmErr := e.evmCache.Error(); evmErr != nil {
+			return shim.Error(fmt.Sprintf("failed to set the contract nonce: %s ", evmErr))
+		}
+	}
+
+	e.evmCache.SetPermission(contractAddr, ContractPermFlags, true)
+	if evmErr := e.evmCache.Error(); evmErr != nil {
+		return shim.Error(fmt.Sprintf("failed to set contract account permissions: %s ", evmErr))
+	}
+
+	rtCode, evmErr := e.vm.Call(e.evmCache, e.eventSink, e.caller, contractAddr, input, input, value, gas)
+	if evmErr != nil {
+		return errorResponse("failed to deploy code", rtCode, evmErr)
+	}
+	if rtCode == nil {
+		return shim.Error(fmt.Sprintf("nil bytecode"))
+	}
+
+	e.evmCache.InitCode(contractAddr, rtCode)
+	if evmErr := e.evmCache.Error(); evmErr != nil {
+		return shim.Error(fmt.Sprintf("failed to update contract account: %s", evmErr))
+	}
+
+	e.nonce++
+	e.calls++
+	return shim.Success(contractAddr.Bytes())
+}
+
+// call runs the code of the callee with the given input. The payload of a
+// successful response is the output of the callee.
+func (e *executor) call(calleeAddr crypto.Address, input []byte, value uint64, gas *uint64) pb.Response {
+	logger.Debugf("Invoke contract at %x", calleeAddr.Bytes())
+
+	if err := checkCaller(e.stub, calleeAddr); err != nil {
+		return shim.Error(fmt.Sprintf("unauthorized: %s", err))
+	}
+
+	if err := e.state.checkCall(calleeAddr); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	calleeCode := e.evmCache.GetCode(calleeAddr)
+	if evmErr := e.evmCache.Error(); evmErr != nil {
+		return shim.Error(fmt.Sprintf("failed to retrieve contract code: %s", evmErr))
+	}
+
+	// Value can be sent to accounts which do not exist yet, as in Ethereum
+	if value > 0 && !e.evmCache.Exists(calleeAddr) {
+		e.evmCache.CreateAccount(calleeAddr)
+		if evmErr := e.evmCache.Error(); evmErr != nil {
+			return shim.Error(fmt.Sprintf("failed to create the callee account: %s", evmErr))
+		}
+	}
+
+	output, evmErr := e.vm.Call(e.evmCache, e.eventSink, e.caller, calleeAddr, calleeCode, input, value, gas)
+	if evmErr != nil {
+		return errorResponse("failed to execute contract", output, evmErr)
+	}
+
+	e.nonce++
+	e.calls++
+	return shim.Success(output)
+}
+
+// commit sets the events of the executed calls as the Fabric event of the
+// transaction, syncs their writes to the ledger along with the writes of the
+// precompiles they called and records the transaction. Nothing is recorded
+// when the calls did not modify any EVM state.
+func (e *executor) commit(eventName string) error {
+	if err := e.eventSink.Flush(eventName); err != nil {
+		return fmt.Errorf("error in Flush: %s", err)
+	}
+
+	// Sync is required for evm to send writes to the statemanager.
+	if evmErr := e.evmCache.Sync(); evmErr != nil {
+		if e.state.rejected != nil {
+			return e.state.rejected
+		}
+		return fmt.Errorf("failed to sync: %s", evmErr)
+	}
+
+	// A paused contract can only be executed by queries
+	if e.state.modified && e.state.pausedCall != nil {
+		return e.state.pausedCall
+	}
+
+	if err := e.state.applyWrites(); err != nil {
+		return err
+	}
+
+	// Without nonces, the transaction does not count towards the nonce of
+	// its sender
+	var calls uint64
+	if e.nonces {
+		calls = e.calls
+	}
+	if err := commitTransaction(e.stub, e.state, e.params, e.caller, calls); err != nil {
+		return fmt.Errorf("failed to commit transaction: %s", err)
+	}
+	return nil
+}
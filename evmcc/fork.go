@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// LegacyFork is the fork used when none has been set at Init. It keeps the
+// opcodes of the Burrow EVM as they were before forks could be selected, and
+// the addresses of contracts derived from the ID of the transaction which
+// created them.
+const LegacyFork = "legacy"
+
+// evmFork is an EVM fork evmcc supports. Its options enable the opcodes it
+// introduced. When it has nonces, each call or deployment of a transaction
+// increments the nonce of its sender, contracts start with nonce 1 and the
+// addresses of contracts are derived from the address and nonce of their
+// creator, as in Ethereum.
+type evmFork struct {
+	options func(chainID uint64) []func(*evm.VM)
+	nonces  bool
+}
+
+// forks maps the name of each EVM fork evmcc supports to the fork. The names
+// match the EVM versions of the Solidity compiler.
+var forks = map[string]evmFork{
+	LegacyFork: {
+		options: func(uint64) []func(*evm.VM) {
+			return nil
+		},
+	},
+	// Constantinople and Petersburg only differ in the gas of SSTORE, which
+	// the Burrow EVM does not meter.
+	"constantinople": {options: constantinople, nonces: true},
+	"petersburg":     {options: constantinople, nonces: true},
+	"istanbul": {
+		options: func(chainID uint64) []func(*evm.VM) {
+			return append(constantinople(chainID), evm.EIP1344(chainID), evm.EIP1884)
+		},
+		nonces: true,
+	},
+}
+
+// constantinople enables CREATE2 as specified by EIP-1014. The shift opcodes
+// of EIP-145 and EXTCODEHASH of EIP-1052 are enabled in every fork.
+func constantinople(uint64) []func(*evm.VM) {
+	return []func(*evm.VM){evm.EIP1014}
+}
+
+// getVMOptions returns the options of the EVM for the fork set at Init and
+// whether the fork has nonces.
+func getVMOptions(stub shim.ChaincodeStubInterface) ([]func(*evm.VM), bool, error) {
+	fork, err := stub.GetState(ForkKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get fork: %s", err)
+	}
+
+	name := string(fork)
+	if name == "" {
+		name = LegacyFork
+	}
+
+	f, ok := forks[name]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown fork %q", name)
+	}
+
+	chainID, err := getChainID(stub)
+	if err != nil {
+		return nil, false, err
+	}
+
+	options := f.options(chainID)
+	if f.nonces {
+		options = append(options, evm.ContractNonces(NewContractAddress))
+	}
+	return options, f.nonces, nil
+}
+
+// getChainID returns the chain ID returned by the CHAINID opcode, which is
+// zero unless one has been set at Init.
+func getChainID(stub shim.ChaincodeStubInterface) (uint64, error) {
+	chainIDBytes, err := stub.GetState(ChainIDKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chain ID: %s", err)
+	}
+
+	if len(chainIDBytes) == 0 {
+		return 0, nil
+	}
+
+	chainID, err := strconv.ParseUint(string(chainIDBytes), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse chain ID: %s", err)
+	}
+	return chainID, nil
+}
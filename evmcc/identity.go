@@ -0,0 +1,112 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/msp"
+)
+
+// attributesOID is the ASN.1 object identifier of the certificate extension
+// in which the Fabric CA stores the attributes of an identity.
+var attributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// creatorIdentity is the identity which submitted the transaction.
+type creatorIdentity struct {
+	mspID string
+	cert  *x509.Certificate
+}
+
+// getCreator returns the identity which submitted the transaction.
+func getCreator(stub shim.ChaincodeStubInterface) (*creatorIdentity, error) {
+	creatorBytes, err := stub.GetCreator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get creator: %s", err)
+	}
+
+	si := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creatorBytes, si); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal serialized identity: %s", err)
+	}
+
+	bl, _ := pem.Decode(si.IdBytes)
+	if bl == nil {
+		return nil, fmt.Errorf("no pem data found")
+	}
+
+	cert, err := x509.ParseCertificate(bl.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %s", err)
+	}
+
+	return &creatorIdentity{mspID: si.GetMspid(), cert: cert}, nil
+}
+
+// attributes returns the attributes the Fabric CA stored in the certificate
+// of the identity. Certificates without attributes have none.
+func (c *creatorIdentity) attributes() (map[string]string, error) {
+	for _, ext := range c.cert.Extensions {
+		if !ext.Id.Equal(attributesOID) {
+			continue
+		}
+
+		attrs := struct {
+			Attrs map[string]string `json:"attrs"`
+		}{}
+		if err := json.Unmarshal(ext.Value, &attrs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal certificate attributes: %s", err)
+		}
+		return attrs.Attrs, nil
+	}
+	return nil, nil
+}
+
+// getCreatorMSPID returns the MSP ID of the identity which submitted the
+// transaction.
+func getCreatorMSPID(stub shim.ChaincodeStubInterface) (string, error) {
+	creatorBytes, err := stub.GetCreator()
+	if err != nil {
+		return "", fmt.Errorf("failed to get creator: %s", err)
+	}
+
+	si := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(creatorBytes, si); err != nil {
+		return "", fmt.Errorf("failed to unmarshal serialized identity: %s", err)
+	}
+
+	return si.GetMspid(), nil
+}
+
+// checkAdmin returns an error unless the transaction was submitted by a
+// member of the admin MSP set at Init.
+func checkAdmin(stub shim.ChaincodeStubInterface) error {
+	admin, err := stub.GetState(AdminKey)
+	if err != nil {
+		return fmt.Errorf("failed to get admin: %s", err)
+	}
+
+	if len(admin) == 0 {
+		return fmt.Errorf("no admin has been set at Init")
+	}
+
+	mspID, err := getCreatorMSPID(stub)
+	if err != nil {
+		return err
+	}
+
+	if mspID != string(admin) {
+		return fmt.Errorf("%s is not the admin MSP", mspID)
+	}
+	return nil
+}
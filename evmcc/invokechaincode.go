@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// ChaincodePrecompileAddress is the address of the precompile through which
+// contracts invoke other chaincodes. Its interface is FabricChaincode in
+// solidity/FabricChaincode.sol.
+var ChaincodePrecompileAddress = crypto.Address{18: 0xfa, 19: 0xb1}
+
+func init() {
+	registerPrecompile(ChaincodePrecompileAddress, "FabricChaincode", map[string]precompileFunction{
+		"invokeChaincode(string,bytes[],string)": invokeChaincode,
+	})
+}
+
+// invokeChaincode invokes a chaincode on a channel, which is the channel of the
+// transaction when it is empty, and returns the status, payload and message of
+// its response. The called chaincode may write to the ledger, so the invocation
+// counts as a write of the calling contract.
+func invokeChaincode(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	name, err := args.String(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chaincode name: %s", err)
+	}
+
+	ccArgs, err := args.BytesArray(1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chaincode arguments: %s", err)
+	}
+
+	channel, err := args.String(2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel: %s", err)
+	}
+
+	if err := ctx.write(caller, fmt.Sprintf("invoke chaincode %s on channel %q", name, channel), nil); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("Contract %s invokes chaincode %s on channel %q", caller, name, channel)
+	res := ctx.stub.InvokeChaincode(name, ccArgs, channel)
+	return abiEncode(int64(res.Status), res.Payload, res.Message), nil
+}
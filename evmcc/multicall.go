@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// MulticallResult is the result of one of the calls of a multicall query: the
+// hex output of the call, or the error of a failed call along with the hex
+// revert data the callee returned and whether the callee reverted.
+type MulticallResult struct {
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Reverted bool   `json:"reverted,omitempty"`
+}
+
+// multicall runs a list of calls, encoded as the calls of a batch, and returns
+// the JSON list of their results. Each call runs on its own view of the latest
+// state, as a query of the call alone would, and a failed call does not fail
+// the others. The writes of the calls, including those of the precompiles, are
+// never committed, so multicall is meant to be queried.
+func (evmcc *EvmChaincode) multicall(stub shim.ChaincodeStubInterface, callsArg []byte) pb.Response {
+	var calls []BatchCall
+	if err := json.Unmarshal(callsArg, &calls); err != nil {
+		return shim.Error(fmt.Sprintf("failed to unmarshal calls: %s", err))
+	}
+
+	results := make([]MulticallResult, len(calls))
+	for i, call := range calls {
+		result, err := runMulticall(stub, call)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		results[i] = result
+	}
+
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal results: %s", err))
+	}
+	return shim.Success(resultsBytes)
+}
+
+// runMulticall runs one of the calls of a multicall on its own executor. It
+// only returns an error when the executor cannot be created.
+func runMulticall(stub shim.ChaincodeStubInterface, call BatchCall) (MulticallResult, error) {
+	ex, err := newExecutor(stub)
+	if err != nil {
+		return MulticallResult{}, err
+	}
+	defer ex.close()
+
+	calleeAddr, input, gas, value, err := parseBatchCall(call, ex.params.GasLimit)
+	if err != nil {
+		return MulticallResult{Error: err.Error()}, nil
+	}
+
+	if calleeAddr == crypto.ZeroAddress {
+		return MulticallResult{Error: "deployments are not supported by multicall"}, nil
+	}
+
+	res := ex.call(calleeAddr, input, value, &gas)
+	result := MulticallResult{Output: hex.EncodeToString(res.Payload)}
+	if res.Status != shim.OK {
+		result.Error = res.Message
+		result.Reverted = res.Status == RevertedStatus
+	}
+	return result, nil
+}
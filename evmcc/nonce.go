@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/burrow/acm"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/crypto/sha3"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// NewContractAddress returns the address of a contract deployed or created by
+// sender when sender has the given nonce, which is the rightmost 160 bits of the
+// keccak256 hash of the RLP encoding of [sender, nonce] as in Ethereum.
+func NewContractAddress(sender crypto.Address, nonce uint64) crypto.Address {
+	// RLP encoding of the nonce: zero is the empty string, values below 0x80
+	// are a single byte and larger values are prefixed with their length
+	var encodedNonce []byte
+	switch {
+	case nonce == 0:
+		encodedNonce = []byte{0x80}
+	case nonce < 0x80:
+		encodedNonce = []byte{byte(nonce)}
+	default:
+		var b []byte
+		for n := nonce; n > 0; n >>= 8 {
+			b = append([]byte{byte(n)}, b...)
+		}
+		encodedNonce = append([]byte{0x80 + byte(len(b))}, b...)
+	}
+
+	// The list is always shorter than 56 bytes, so its prefix is a single byte
+	encodedSender := append([]byte{0x80 + crypto.AddressLength}, sender.Bytes()...)
+	payloadLength := len(encodedSender) + len(encodedNonce)
+	encoded := make([]byte, 0, 1+payloadLength)
+	encoded = append(encoded, 0xc0+byte(payloadLength))
+	encoded = append(encoded, encodedSender...)
+	encoded = append(encoded, encodedNonce...)
+
+	var addr crypto.Address
+	copy(addr[:], sha3.Sha3(encoded)[12:])
+	return addr
+}
+
+// legacyContractAddress returns the address of a contract deployed by sender
+// when the fork has no nonces, which is derived from the ID of the transaction
+// and the number of calls of the transaction before the deployment. The first
+// deployment of a transaction gets the address derived from its ID alone.
+func legacyContractAddress(sender crypto.Address, txID string, calls uint64) crypto.Address {
+	nonceInput := txID
+	if calls > 0 {
+		nonceInput += ":" + strconv.FormatUint(calls, 10)
+	}
+	return crypto.NewContractAddress(sender, crypto.Nonce(sender, []byte(nonceInput)))
+}
+
+// getNonce returns the nonce of an account, which is the number of
+// transactions it sent that modified the state of the EVM. Accounts which do
+// not exist have a zero nonce.
+func getNonce(state statemanager.StateManager, addr crypto.Address) (uint64, error) {
+	acct, err := state.GetAccount(addr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get account: %s", err)
+	}
+
+	if acct == nil {
+		return 0, nil
+	}
+	return acct.Sequence, nil
+}
+
+// incrementNonce adds the number of calls of a transaction to the nonce of its
+// sender, creating the account of the sender if it does not exist yet.
+func incrementNonce(state statemanager.StateManager, sender crypto.Address, calls uint64) error {
+	acct, err := state.GetAccount(sender)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %s", err)
+	}
+
+	if acct == nil {
+		acct = &acm.Account{Address: sender}
+	}
+
+	acct.Sequence += calls
+	return state.UpdateAccount(acct)
+}
+
+func (evmcc *EvmChaincode) getNonce(stub shim.ChaincodeStubInterface, address []byte) pb.Response {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	nonce, err := getNonce(statemanager.NewStateManager(stub), addr)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success([]byte(strconv.FormatUint(nonce, 10)))
+}
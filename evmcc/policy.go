@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ouPrefix marks the organizational units in the list parsed by parsePolicy.
+const ouPrefix = "ou:"
+
+// Policy lists the identities allowed to perform an action. An identity is
+// allowed when it is a member of one of the MSPs, its certificate has one of
+// the organizational units or holds one of the attributes with the given
+// value. An empty policy allows everyone.
+type Policy struct {
+	MSPIDs     []string          `json:"mspids,omitempty"`
+	OUs        []string          `json:"ous,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// parsePolicy parses a comma separated list of MSP IDs, organizational units
+// prefixed with `ou:` and `name=value` certificate attributes, such as
+// `Org1MSP,ou:finance,evm.deployer=true`.
+func parsePolicy(value string) (*Policy, error) {
+	policy := &Policy{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) == 1 {
+			if strings.HasPrefix(entry, ouPrefix) {
+				ou := strings.TrimPrefix(entry, ouPrefix)
+				if ou == "" {
+					return nil, fmt.Errorf("organizational unit must not be empty in %q", entry)
+				}
+				policy.OUs = append(policy.OUs, ou)
+				continue
+			}
+			policy.MSPIDs = append(policy.MSPIDs, entry)
+			continue
+		}
+
+		if kv[0] == "" {
+			return nil, fmt.Errorf("attribute name must not be empty in %q", entry)
+		}
+		if policy.Attributes == nil {
+			policy.Attributes = make(map[string]string)
+		}
+		policy.Attributes[kv[0]] = kv[1]
+	}
+	return policy, nil
+}
+
+func (p *Policy) isEmpty() bool {
+	return len(p.MSPIDs) == 0 && len(p.OUs) == 0 && len(p.Attributes) == 0
+}
+
+// allows returns whether the identity satisfies the policy.
+func (p *Policy) allows(identity *creatorIdentity) (bool, error) {
+	if p.isEmpty() {
+		return true, nil
+	}
+
+	for _, mspID := range p.MSPIDs {
+		if identity.mspID == mspID {
+			return true, nil
+		}
+	}
+
+	for _, ou := range p.OUs {
+		for _, identityOU := range identity.cert.Subject.OrganizationalUnit {
+			if identityOU == ou {
+				return true, nil
+			}
+		}
+	}
+
+	if len(p.Attributes) == 0 {
+		return false, nil
+	}
+
+	attrs, err := identity.attributes()
+	if err != nil {
+		return false, err
+	}
+
+	for name, value := range p.Attributes {
+		if v, ok := attrs[name]; ok && v == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getPolicy returns the policy stored under key, which is empty when none has
+// been stored.
+func getPolicy(stub shim.ChaincodeStubInterface, key string) (*Policy, error) {
+	policyBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy: %s", err)
+	}
+
+	policy := &Policy{}
+	if len(policyBytes) == 0 {
+		return policy, nil
+	}
+
+	if err := json.Unmarshal(policyBytes, policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy: %s", err)
+	}
+	return policy, nil
+}
+
+// putPolicy stores the policy under key. Empty policies are deleted.
+func putPolicy(stub shim.ChaincodeStubInterface, key string, policy *Policy) error {
+	if policy.isEmpty() {
+		return stub.DelState(key)
+	}
+
+	policyBytes, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal policy: %s", err)
+	}
+	return stub.PutState(key, policyBytes)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/errors"
+	"github.com/hyperledger/burrow/execution/evm/abi"
+	"github.com/hyperledger/burrow/logging"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+const (
+	gasPrecompileBase uint64 = 1
+	gasPrecompileWord uint64 = 1
+)
+
+// NativeContextAddress is the reserved address whose storage the precompiles
+// read to find the context of the transaction calling them. Burrow registers
+// native contracts globally while transactions are executed concurrently, so
+// each transaction registers its context under a handle, which its state
+// returns as the storage of this address at key 0. The other keys of its
+// storage record the writes of the precompiles, see nativeContext.write.
+var NativeContextAddress = crypto.Address{18: 0xfa, 19: 0xb0}
+
+var (
+	nativeContexts     sync.Map
+	lastNativeContext  uint64
+	errNoNativeContext = fmt.Errorf("precompiles can only be called by transactions of evmcc")
+)
+
+// nativeContext is the context precompiles run in: the stub of the
+// transaction calling them, its EVM state and the state of the precompile
+// call.
+type nativeContext struct {
+	stub      shim.ChaincodeStubInterface
+	state     *trackingState
+	callState evm.Interface
+}
+
+// nativeWrite is a write of a precompile outside of the EVM state. It is
+// synced when the calls which made it did not revert. Traces list it by its
+// description.
+type nativeWrite struct {
+	description string
+	apply       func() error
+	synced      bool
+}
+
+// registerNativeContext registers the context of a transaction and sets the
+// handle to look it up as the storage of NativeContextAddress in its state.
+func registerNativeContext(stub shim.ChaincodeStubInterface, state *trackingState) {
+	state.nativeContext = binary.Uint64ToWord256(atomic.AddUint64(&lastNativeContext, 1))
+	nativeContexts.Store(state.nativeContext, &nativeContext{stub: stub, state: state})
+}
+
+// unregisterNativeContext removes the context of a transaction once it has
+// been executed.
+func unregisterNativeContext(state *trackingState) {
+	nativeContexts.Delete(state.nativeContext)
+}
+
+func getNativeContext(st evm.Interface) (*nativeContext, error) {
+	handle := st.GetStorage(NativeContextAddress, binary.Zero256)
+	if err := st.Error(); err != nil {
+		return nil, err
+	}
+
+	ctx, ok := nativeContexts.Load(handle)
+	if !ok {
+		return nil, errNoNativeContext
+	}
+	return ctx.(*nativeContext), nil
+}
+
+// write is called by precompiles to write outside of the EVM state on behalf
+// of the contract at address. The transaction then counts as modifying the
+// EVM state, and the write is rejected as a write to the state of the contract
+// would be. Otherwise it is recorded in the state of the call, so that it is
+// dropped along with the EVM writes of the calls which revert, and apply is
+// called once the EVM state has been synced. Writes which cannot wait, such as
+// chaincode invocations, are made by the precompile itself and recorded with
+// a nil apply, the transaction then fails when one of their calls reverts.
+func (ctx *nativeContext) write(address crypto.Address, description string, apply func() error) error {
+	if err := ctx.state.checkWrite(address); err != nil {
+		return err
+	}
+
+	ctx.state.writes = append(ctx.state.writes, &nativeWrite{description: description, apply: apply})
+	key := binary.Uint64ToWord256(uint64(len(ctx.state.writes)))
+	ctx.callState.SetStorage(NativeContextAddress, key, binary.One256)
+	return ctx.callState.Error()
+}
+
+// precompileFunction is a function of a precompile. It is called with the ABI
+// encoded arguments of the call and returns its ABI encoded return values.
+type precompileFunction func(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error)
+
+// precompile is a native contract of evmcc. As the functions of a Solidity
+// contract, its functions are selected by the first 4 bytes of the input.
+type precompile struct {
+	name      string
+	functions map[abi.FunctionID]precompileFunction
+}
+
+// registerPrecompile registers with burrow a precompile at address, whose
+// functions are given by their Solidity signature. It panics when the address
+// is already registered.
+func registerPrecompile(address crypto.Address, name string, functions map[string]precompileFunction) {
+	p := &precompile{name: name, functions: make(map[abi.FunctionID]precompileFunction, len(functions))}
+	for signature, fn := range functions {
+		p.functions[abi.GetFunctionID(signature)] = fn
+	}
+
+	if !evm.RegisterNativeContract(address, p.dispatch) {
+		panic(fmt.Errorf("could not register precompile %s because address %s is already registered", name, address))
+	}
+}
+
+func (p *precompile) dispatch(st evm.Interface, caller crypto.Address, input []byte, gas *uint64,
+	logger *logging.Logger) ([]byte, error) {
+
+	gasRequired := uint64((len(input)+31)/32)*gasPrecompileWord + gasPrecompileBase
+	if *gas < gasRequired {
+		return nil, errors.ErrorCodeInsufficientGas
+	}
+	*gas -= gasRequired
+
+	if len(input) < abi.FunctionIDSize {
+		return nil, fmt.Errorf("%s requires a 4-byte function selector but the input is only %d bytes long", p.name, len(input))
+	}
+
+	var id abi.FunctionID
+	copy(id[:], input)
+	fn, ok := p.functions[id]
+	if !ok {
+		return nil, fmt.Errorf("%s has no function with selector %x", p.name, id)
+	}
+
+	ctx, err := getNativeContext(st)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", p.name, err)
+	}
+
+	callCtx := &nativeContext{stub: ctx.stub, state: ctx.state, callState: st}
+	output, err := fn(callCtx, caller, abiArgs(input[abi.FunctionIDSize:]))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", p.name, err)
+	}
+	return output, nil
+}
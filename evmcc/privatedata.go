@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// PrivateDataPrecompileAddress is the address of the precompile through which
+// contracts read and write private data collections. Its interface is
+// FabricPrivateData in solidity/FabricPrivateData.sol.
+var PrivateDataPrecompileAddress = crypto.Address{18: 0xfa, 19: 0xb4}
+
+func init() {
+	registerPrecompile(PrivateDataPrecompileAddress, "FabricPrivateData", map[string]precompileFunction{
+		"getPrivateData(string,string)":       getPrivateData,
+		"putPrivateData(string,string,bytes)": putPrivateData,
+		"delPrivateData(string,string)":       delPrivateData,
+		"getPrivateDataHash(string,string)":   getPrivateDataHash,
+	})
+}
+
+// PrivateDataKey returns the key under which a contract stores a key of a
+// private data collection. Keys are prefixed with the address of the contract
+// so contracts cannot read or write the private data of other contracts.
+func PrivateDataKey(contract crypto.Address, key string) string {
+	return strings.ToLower(contract.String()) + ":" + key
+}
+
+// privateDataHashGetter is implemented by the stubs of Fabric versions which
+// return the hash of private data to peers which are not members of the
+// collection.
+type privateDataHashGetter interface {
+	GetPrivateDataHash(collection, key string) ([]byte, error)
+}
+
+func getPrivateData(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	collection, key, err := privateDataArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := ctx.stub.GetPrivateData(collection, PrivateDataKey(caller, key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private data: %s", err)
+	}
+	return abiEncode(value), nil
+}
+
+func putPrivateData(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	collection, key, err := privateDataArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := args.Bytes(2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %s", err)
+	}
+
+	description := fmt.Sprintf("put private data %s in collection %s", PrivateDataKey(caller, key), collection)
+	return nil, ctx.write(caller, description, func() error {
+		if err := ctx.stub.PutPrivateData(collection, PrivateDataKey(caller, key), value); err != nil {
+			return fmt.Errorf("failed to put private data: %s", err)
+		}
+		return nil
+	})
+}
+
+func delPrivateData(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	collection, key, err := privateDataArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("delete private data %s in collection %s", PrivateDataKey(caller, key), collection)
+	return nil, ctx.write(caller, description, func() error {
+		if err := ctx.stub.DelPrivateData(collection, PrivateDataKey(caller, key)); err != nil {
+			return fmt.Errorf("failed to delete private data: %s", err)
+		}
+		return nil
+	})
+}
+
+// getPrivateDataHash returns the hash of the value of a key, which peers that
+// are not members of the collection can read as well. It is zero when the key
+// has no value.
+func getPrivateDataHash(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	collection, key, err := privateDataArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	getter, ok := ctx.stub.(privateDataHashGetter)
+	if !ok {
+		return nil, fmt.Errorf("the chaincode shim does not support private data hashes")
+	}
+
+	hash, err := getter.GetPrivateDataHash(collection, PrivateDataKey(caller, key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private data hash: %s", err)
+	}
+
+	if len(hash) > binary.Word256Length {
+		return nil, fmt.Errorf("private data hash is longer than %d bytes", binary.Word256Length)
+	}
+	return abiEncode(binary.LeftPadWord256(hash)), nil
+}
+
+func privateDataArgs(args abiArgs) (string, string, error) {
+	collection, err := args.String(0)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid collection: %s", err)
+	}
+
+	key, err := args.String(1)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid key: %s", err)
+	}
+	return collection, key, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/burrow/execution/errors"
+	"github.com/hyperledger/burrow/execution/evm/abi"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// RevertedStatus is the status of the error response for an execution reverted
+// by a contract, which tells the reverts apart from the other failures. As for
+// any status from 400, the response is not endorsed.
+const RevertedStatus = 422
+
+var (
+	// errorSelector is the function selector of `Error(string)`, which
+	// Solidity uses to encode the reason given to `require` and `revert`.
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// panicSelector is the function selector of `Panic(uint256)`, which
+	// Solidity uses to encode failed assertions and runtime errors.
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// panicReasons describes the panic codes emitted by the Solidity compiler.
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array access",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+// errorResponse returns the error response for a failed execution of the EVM.
+// When the contract reverted, the status is RevertedStatus, the message the
+// revert message with the decoded revert reason and the payload the raw revert
+// data, so clients can decode it themselves.
+func errorResponse(msg string, output []byte, evmErr errors.CodedError) pb.Response {
+	if evmErr.ErrorCode() != errors.ErrorCodeExecutionReverted {
+		return shim.Error(fmt.Sprintf("%s: %s", msg, evmErr))
+	}
+
+	return pb.Response{
+		Status:  RevertedStatus,
+		Message: revertMessage(output),
+		Payload: output,
+	}
+}
+
+// revertMessage returns a description of the revert data of a contract in the
+// form used by Ethereum clients: `execution reverted`, followed by the reason
+// when one can be decoded.
+func revertMessage(output []byte) string {
+	switch {
+	case bytes.HasPrefix(output, errorSelector):
+		reason, err := abi.UnpackRevert(output)
+		if err == nil {
+			return "execution reverted: " + *reason
+		}
+
+	case bytes.HasPrefix(output, panicSelector) && len(output) == len(panicSelector)+32:
+		code := new(big.Int).SetBytes(output[len(panicSelector):])
+		if code.IsUint64() {
+			if description, ok := panicReasons[code.Uint64()]; ok {
+				return fmt.Sprintf("execution reverted: panic: %s (0x%x)", description, code)
+			}
+		}
+		return fmt.Sprintf("execution reverted: panic: unknown code (0x%x)", code)
+	}
+
+	return "execution reverted"
+}
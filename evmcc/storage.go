@@ -0,0 +1,57 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// getStorageAt returns the 32 byte word stored in a storage slot of an
+// account, hex encoded. Slots which were never written hold zero.
+func (evmcc *EvmChaincode) getStorageAt(stub shim.ChaincodeStubInterface, address, slot []byte) pb.Response {
+	addr, err := parseAddress(address)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	key, err := parseStorageSlot(slot)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	val, err := statemanager.NewStateManager(stub).GetStorage(addr, key)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to get storage: %s", err))
+	}
+
+	return shim.Success([]byte(hex.EncodeToString(val.Bytes())))
+}
+
+// parseStorageSlot decodes a hex encoded storage slot of at most 32 bytes,
+// left padding it to a word. Leading zeros may be omitted.
+func parseStorageSlot(slot []byte) (binary.Word256, error) {
+	s := string(slot)
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return binary.Word256{}, fmt.Errorf("failed to decode storage slot from %s: %s", string(slot), err)
+	}
+
+	if len(b) > binary.Word256Length {
+		return binary.Word256{}, fmt.Errorf("storage slot %s is longer than %d bytes", string(slot), binary.Word256Length)
+	}
+	return binary.LeftPadWord256(b), nil
+}
@@ -0,0 +1,279 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/errors"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm"
+	"github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm/asm"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// TraceResult is the result of a trace query, in the format of the struct
+// logger of geth: the gas used by the call, whether it failed, its hex output
+// and the steps of its execution. Error is the reason a call failed, and
+// Writes describes the writes the call made outside of the EVM state through
+// the precompiles. Only the chaincode invocations are made, the other writes
+// are never applied.
+type TraceResult struct {
+	Gas         uint64      `json:"gas"`
+	Failed      bool        `json:"failed"`
+	ReturnValue string      `json:"returnValue"`
+	Error       string      `json:"error,omitempty"`
+	StructLogs  []StructLog `json:"structLogs"`
+	Writes      []string    `json:"writes,omitempty"`
+}
+
+// StructLog is the state of the EVM before it executed an opcode. The words of
+// the stack, the top of the stack last, and of the memory are hex encoded.
+// Storage holds the storage slots of the contract the call has read or written
+// so far, and is only set for SLOAD and SSTORE.
+type StructLog struct {
+	PC      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   uint64            `json:"depth"`
+	Stack   []string          `json:"stack"`
+	Memory  []string          `json:"memory"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+// trace executes a call or a deployment, encoded as the calls of a batch, on
+// the latest state and returns the JSON TraceResult of its execution. When the
+// serialized identity of a creator is given, such as the creator of a
+// transaction which is replayed, the call is executed on its behalf. The
+// writes of the call are never committed, so trace is meant to be queried.
+func (evmcc *EvmChaincode) trace(stub shim.ChaincodeStubInterface, callArg, creator []byte) pb.Response {
+	var call BatchCall
+	if err := json.Unmarshal(callArg, &call); err != nil {
+		return shim.Error(fmt.Sprintf("failed to unmarshal call: %s", err))
+	}
+
+	if creator != nil {
+		signer, err := stub.GetCreator()
+		if err != nil {
+			return shim.Error(fmt.Sprintf("failed to get creator: %s", err))
+		}
+		if !bytes.Equal(creator, signer) {
+			stub = &traceStub{ChaincodeStubInterface: stub, creator: creator}
+		}
+	}
+
+	tracer := &structLogger{storage: make(map[crypto.Address]map[string]string)}
+	ex, err := newExecutor(stub, evm.StepTracer(tracer.step), evm.MemoryProvider(newTracedMemory))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer ex.close()
+
+	calleeAddr, input, txGas, value, err := parseBatchCall(call, ex.params.GasLimit)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	gas := txGas
+
+	var res pb.Response
+	if calleeAddr == crypto.ZeroAddress {
+		res = ex.deploy(input, value, &gas)
+	} else {
+		res = ex.call(calleeAddr, input, value, &gas)
+	}
+	tracer.setGasCosts(gas)
+
+	result := TraceResult{
+		Gas:         txGas - gas,
+		Failed:      res.Status != shim.OK,
+		ReturnValue: hex.EncodeToString(res.Payload),
+		Error:       res.Message,
+		StructLogs:  tracer.logs,
+		Writes:      ex.nativeWrites(),
+	}
+	if result.StructLogs == nil {
+		result.StructLogs = []StructLog{}
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal trace: %s", err))
+	}
+	return shim.Success(resultBytes)
+}
+
+// traceStub is the stub of a trace executed on behalf of another creator than
+// the signer of the trace. The calls see that creator, but chaincodes are not
+// invoked, as the trace could be submitted and their writes committed on
+// behalf of that creator.
+type traceStub struct {
+	shim.ChaincodeStubInterface
+	creator []byte
+}
+
+func (s *traceStub) GetCreator() ([]byte, error) {
+	return s.creator, nil
+}
+
+func (s *traceStub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	return shim.Error("chaincodes cannot be invoked by a trace on behalf of another creator")
+}
+
+// nativeWrites returns the descriptions of the writes of the precompiles which
+// were not dropped along with a reverted call, in the order they were made.
+func (e *executor) nativeWrites() []string {
+	var writes []string
+	for i, w := range e.state.writes {
+		if e.evmCache.GetStorage(NativeContextAddress, binary.Uint64ToWord256(uint64(i+1))) == binary.One256 {
+			writes = append(writes, w.description)
+		}
+	}
+	return writes
+}
+
+// structLogger records the steps of an execution as struct logs.
+type structLogger struct {
+	logs []StructLog
+	// storage holds the storage slots read or written by the execution for
+	// each contract
+	storage map[crypto.Address]map[string]string
+}
+
+func (l *structLogger) step(step *evm.Step) {
+	log := StructLog{
+		PC:     step.PC,
+		Op:     step.Op.String(),
+		Gas:    step.Gas,
+		Depth:  step.Depth,
+		Stack:  make([]string, len(step.Stack)),
+		Memory: []string{},
+	}
+
+	for i, word := range step.Stack {
+		log.Stack[i] = hex.EncodeToString(word.Bytes())
+	}
+
+	if memory, ok := step.Memory.(*tracedMemory); ok {
+		for _, word := range memory.words() {
+			log.Memory = append(log.Memory, hex.EncodeToString(word))
+		}
+	}
+
+	top := len(step.Stack) - 1
+	switch {
+	case step.Op == asm.SLOAD && top >= 0:
+		key := step.Stack[top]
+		l.store(step.Address, key, step.State.GetStorage(step.Address, key))
+		log.Storage = l.copyStorage(step.Address)
+	case step.Op == asm.SSTORE && top >= 1:
+		l.store(step.Address, step.Stack[top], step.Stack[top-1])
+		log.Storage = l.copyStorage(step.Address)
+	}
+
+	l.logs = append(l.logs, log)
+}
+
+func (l *structLogger) store(address crypto.Address, key, value binary.Word256) {
+	if l.storage[address] == nil {
+		l.storage[address] = make(map[string]string)
+	}
+	l.storage[address][hex.EncodeToString(key.Bytes())] = hex.EncodeToString(value.Bytes())
+}
+
+func (l *structLogger) copyStorage(address crypto.Address) map[string]string {
+	storage := make(map[string]string, len(l.storage[address]))
+	for key, value := range l.storage[address] {
+		storage[key] = value
+	}
+	return storage
+}
+
+// setGasCosts sets the gas cost of each step as the gas consumed until the
+// next step of the same call. The last step of the outermost call consumed the
+// gas down to gasLeft, the cost of the last step of an inner call is unknown
+// and left at zero.
+func (l *structLogger) setGasCosts(gasLeft uint64) {
+	// next maps each depth to the index of the next step at that depth
+	next := make(map[uint64]int)
+	for i := len(l.logs) - 1; i >= 0; i-- {
+		depth := l.logs[i].Depth
+		// Steps of calls which start after step i are not steps of the call
+		// which step i is part of
+		for d := range next {
+			if d > depth {
+				delete(next, d)
+			}
+		}
+
+		if j, ok := next[depth]; ok {
+			l.logs[i].GasCost = l.logs[i].Gas - l.logs[j].Gas
+		} else if depth == 1 {
+			l.logs[i].GasCost = l.logs[i].Gas - gasLeft
+		}
+		next[depth] = i
+	}
+}
+
+// tracedMemory is the memory of a traced call. It records the size of the
+// memory the call has used, which the EVM of Ethereum expands word by word,
+// while the memory of the Burrow EVM starts with a large capacity.
+type tracedMemory struct {
+	evm.Memory
+	size uint64
+}
+
+func newTracedMemory(errSink errors.Sink) evm.Memory {
+	return &tracedMemory{Memory: evm.DefaultDynamicMemoryProvider(errSink)}
+}
+
+func (m *tracedMemory) Read(offset, length *big.Int) []byte {
+	value := m.Memory.Read(offset, length)
+	if value != nil {
+		m.expand(offset, length)
+	}
+	return value
+}
+
+func (m *tracedMemory) Write(offset *big.Int, value []byte) {
+	m.Memory.Write(offset, value)
+	m.expand(offset, big.NewInt(int64(len(value))))
+}
+
+// expand extends the size of the memory to the words accessed by a read or
+// write of length bytes at offset, when they are within its capacity.
+func (m *tracedMemory) expand(offset, length *big.Int) {
+	if length.Sign() == 0 {
+		return
+	}
+
+	end := new(big.Int).Add(offset, length)
+	if end.Cmp(m.Capacity()) > 0 {
+		return
+	}
+
+	size := (end.Uint64() + binary.Word256Length - 1) / binary.Word256Length * binary.Word256Length
+	if size > m.size {
+		m.size = size
+	}
+}
+
+// words returns the words of the used memory.
+func (m *tracedMemory) words() [][]byte {
+	words := make([][]byte, 0, m.size/binary.Word256Length)
+	for offset := uint64(0); offset < m.size; offset += binary.Word256Length {
+		offsetBig := new(big.Int).SetUint64(offset)
+		words = append(words, m.Memory.Read(offsetBig, big.NewInt(binary.Word256Length)))
+	}
+	return words
+}
@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// TransientInputKey is the key of the transient map which holds the hex input
+// of a transaction whose input argument is empty. The transient map of a
+// proposal is not recorded in the ledger, so the input stays confidential.
+const TransientInputKey = "evmcc:input"
+
+// TransientInputPartPrefix prefixes the keys of the transient map which hold
+// hex parts of the input of a transaction, such as selected ABI arguments. The
+// prefix is followed by the decimal offset in bytes at which the part replaces
+// the input, which is extended when the part goes past its end. A part cannot
+// start past the end of the input, including the parts at lower offsets. The
+// argument i of a call is at offset 4+32*i.
+const TransientInputPartPrefix = "evmcc:input:"
+
+// transientInput returns the input of a transaction, completed with the input
+// or the parts of the input held in the transient map of the proposal.
+func transientInput(stub shim.ChaincodeStubInterface, input []byte) ([]byte, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient map: %s", err)
+	}
+
+	if hexInput, ok := transient[TransientInputKey]; ok {
+		if len(input) != 0 {
+			return nil, fmt.Errorf("input is given both as argument and in the transient map")
+		}
+
+		if input, err = hex.DecodeString(string(hexInput)); err != nil {
+			return nil, fmt.Errorf("failed to decode transient input bytes: %s", err)
+		}
+	}
+
+	// The parts are applied in the order of their offsets, so every endorser
+	// builds the same input
+	var offsets []uint64
+	parts := make(map[uint64][]byte)
+	for key, hexPart := range transient {
+		if !strings.HasPrefix(key, TransientInputPartPrefix) {
+			continue
+		}
+
+		offset, err := strconv.ParseUint(strings.TrimPrefix(key, TransientInputPartPrefix), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset of transient input part %s", key)
+		}
+
+		part, err := hex.DecodeString(string(hexPart))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode transient input part %s: %s", key, err)
+		}
+
+		offsets = append(offsets, offset)
+		parts[offset] = part
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	for _, offset := range offsets {
+		if offset > uint64(len(input)) {
+			return nil, fmt.Errorf("transient input part %s%d starts past the end of the input of %d bytes", TransientInputPartPrefix, offset, len(input))
+		}
+
+		part := parts[offset]
+		if end := int(offset) + len(part); end > len(input) {
+			input = append(input[:offset], part...)
+			continue
+		}
+		copy(input[offset:], part)
+	}
+	return input, nil
+}
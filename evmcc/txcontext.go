@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/burrow/crypto"
+)
+
+// TxContextPrecompileAddress is the address of the precompile through which
+// contracts read the context of the Fabric transaction. Its interface is
+// FabricTxContext in solidity/FabricTxContext.sol.
+var TxContextPrecompileAddress = crypto.Address{18: 0xfa, 19: 0xb2}
+
+func init() {
+	registerPrecompile(TxContextPrecompileAddress, "FabricTxContext", map[string]precompileFunction{
+		"txID()":         txID,
+		"txTimestamp()":  txTimestamp,
+		"channelID()":    channelID,
+		"creatorMSPID()": creatorMSPID,
+	})
+}
+
+// The context is taken from the proposal, which is the same for every
+// endorser, so the values are deterministic.
+
+func txID(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	return abiEncode(ctx.stub.GetTxID()), nil
+}
+
+// txTimestamp returns the seconds and nanoseconds of the timestamp the client
+// set in the proposal.
+func txTimestamp(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	ts, err := ctx.stub.GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %s", err)
+	}
+	return abiEncode(ts.GetSeconds(), int64(ts.GetNanos())), nil
+}
+
+func channelID(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	return abiEncode(ctx.stub.GetChannelID()), nil
+}
+
+func creatorMSPID(ctx *nativeContext, caller crypto.Address, args abiArgs) ([]byte, error) {
+	mspID, err := getCreatorMSPID(ctx.stub)
+	if err != nil {
+		return nil, err
+	}
+	return abiEncode(mspID), nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fab3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/rpc/v2/json2"
+
+	"github.com/hyperledger/fabric-chaincode-evm/fab3/types"
+)
+
+// batchHandler serves JSON-RPC batches, which are arrays of requests, and
+// passes any other request to the rpc server. The eth_call requests of a batch
+// are served together by the Multicall of the ethservice, which runs them in a
+// single query of the EVM chaincode, the other requests are passed one by one
+// to the rpc server.
+//
+// https://www.jsonrpc.org/specification#batch
+type batchHandler struct {
+	rpcServer http.Handler
+	service   EthService
+}
+
+type batchRequest struct {
+	Version string            `json:"jsonrpc"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+	ID      *json.RawMessage  `json:"id"`
+}
+
+type batchResponse struct {
+	Version string           `json:"jsonrpc"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *json2.Error     `json:"error,omitempty"`
+	ID      *json.RawMessage `json:"id"`
+}
+
+func (h *batchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		h.rpcServer.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, batchResponse{Version: "2.0", Error: &json2.Error{Code: json2.E_PARSE, Message: err.Error()}})
+		return
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		h.rpcServer.ServeHTTP(w, r)
+		return
+	}
+
+	var requests []json.RawMessage
+	if err := json.Unmarshal(trimmed, &requests); err != nil {
+		writeJSON(w, batchResponse{Version: "2.0", Error: &json2.Error{Code: json2.E_PARSE, Message: err.Error()}})
+		return
+	}
+
+	if len(requests) == 0 {
+		writeJSON(w, batchResponse{Version: "2.0", Error: &json2.Error{Code: json2.E_INVALID_REQ, Message: "empty batch"}})
+		return
+	}
+
+	responses := make([]json.RawMessage, len(requests))
+	served := h.serveCalls(r, requests, responses)
+	for i, request := range requests {
+		if !served[i] {
+			responses[i] = h.serveRequest(r, request)
+		}
+	}
+
+	// Notifications do not have a response
+	var batch []json.RawMessage
+	for _, response := range responses {
+		if len(response) != 0 {
+			batch = append(batch, response)
+		}
+	}
+	if len(batch) != 0 {
+		writeJSON(w, batch)
+	}
+}
+
+// serveCalls serves the eth_call requests of a batch with a single Multicall
+// and returns which requests were served. Each request gets the result or the
+// error of its own call, as with eth_call.
+func (h *batchHandler) serveCalls(r *http.Request, requests, responses []json.RawMessage) []bool {
+	served := make([]bool, len(requests))
+
+	var (
+		indexes []int
+		ids     []*json.RawMessage
+		calls   []types.EthArgs
+	)
+	for i, rawRequest := range requests {
+		var request batchRequest
+		if err := json.Unmarshal(rawRequest, &request); err != nil {
+			continue
+		}
+		if request.Version != "2.0" || request.Method != "eth_call" || len(request.Params) == 0 {
+			continue
+		}
+
+		// Calls with transient data are served one by one, as Multicall does
+		// not support them
+		var args types.EthArgs
+		if err := json.Unmarshal(request.Params[0], &args); err != nil || len(args.Transient) != 0 {
+			continue
+		}
+
+		indexes = append(indexes, i)
+		ids = append(ids, request.ID)
+		calls = append(calls, args)
+	}
+
+	if len(calls) == 0 {
+		return served
+	}
+
+	var results []types.CallResult
+	err := h.service.Multicall(r, &calls, &results)
+	for j, i := range indexes {
+		served[i] = true
+		if ids[j] == nil {
+			continue
+		}
+
+		response := batchResponse{Version: "2.0", ID: ids[j]}
+		switch {
+		case err != nil:
+			response.Error = &json2.Error{Code: json2.E_SERVER, Message: err.Error()}
+		case j >= len(results):
+			response.Error = &json2.Error{Code: json2.E_SERVER, Message: "no result for the call"}
+		default:
+			response.Result, response.Error = callResponse(results[j])
+		}
+
+		responseBytes, marshalErr := json.Marshal(response)
+		if marshalErr != nil {
+			responseBytes = errorResponse(ids[j], marshalErr)
+		}
+		responses[i] = responseBytes
+	}
+	return served
+}
+
+// serveRequest passes a single request of a batch to the rpc server and
+// returns its response.
+func (h *batchHandler) serveRequest(r *http.Request, request json.RawMessage) json.RawMessage {
+	req, err := http.NewRequest(r.Method, r.URL.String(), bytes.NewReader(request))
+	if err != nil {
+		return errorResponse(nil, err)
+	}
+	req = req.WithContext(r.Context())
+	req.Header = r.Header
+
+	buffer := &responseBuffer{header: make(http.Header)}
+	h.rpcServer.ServeHTTP(buffer, req)
+
+	response := bytes.TrimSpace(buffer.body.Bytes())
+	if len(response) != 0 && !json.Valid(response) {
+		// The rpc server writes plain text errors for malformed requests
+		var id *json.RawMessage
+		var parsed batchRequest
+		if json.Unmarshal(request, &parsed) == nil {
+			id = parsed.ID
+		}
+		return errorResponse(id, fmt.Errorf("%s", response))
+	}
+	return response
+}
+
+// responseBuffer is an http.ResponseWriter which keeps the body of the
+// response of a request of a batch, the status and headers of the batch are
+// written by batchHandler.
+type responseBuffer struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func (b *responseBuffer) WriteHeader(int) {}
+
+// callResponse returns the result of an eth_call from the result of a call of
+// a Multicall, or the same error eth_call returns when the call failed.
+func callResponse(result types.CallResult) (interface{}, *json2.Error) {
+	if result.Success {
+		return result.ReturnData, nil
+	}
+
+	if result.Reverted {
+		return nil, &json2.Error{Code: ExecutionRevertedCode, Message: result.Error, Data: result.ReturnData}
+	}
+	return nil, &json2.Error{Code: json2.E_SERVER, Message: result.Error}
+}
+
+func errorResponse(id *json.RawMessage, err error) json.RawMessage {
+	responseBytes, _ := json.Marshal(batchResponse{
+		Version: "2.0",
+		Error:   &json2.Error{Code: json2.E_SERVER, Message: err.Error()},
+		ID:      id,
+	})
+	return responseBytes
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -140,7 +140,10 @@ func runFab3(cmd *cobra.Command, args []string) error {
 
 	ethService := fab3.NewEthService(client, ledger, ch, ccid, logger)
 
-	proxy := fab3.NewFab3(ethService, port)
+	debugService := fab3.NewDebugService(client, ledger, ccid, logger)
+	traceService := fab3.NewTraceService(ledger, logger)
+
+	proxy := fab3.NewFab3(ethService, debugService, traceService, port)
 
 	errChan := make(chan error, 1)
 	go func() {
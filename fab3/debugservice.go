@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fab3
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"go.uber.org/zap"
+
+	"github.com/hyperledger/fabric-chaincode-evm/fab3/types"
+)
+
+//go:generate counterfeiter -o ../mocks/fab3/mockdebugservice.go --fake-name MockDebugService ./ DebugService
+
+// DebugService is the debug namespace of the geth json-rpc, which traces the
+// execution of calls by the EVM chaincode.
+type DebugService interface {
+	TraceCall(r *http.Request, args *types.EthArgs, reply *types.TraceResult) error
+	TraceTransaction(r *http.Request, txID *string, reply *types.TraceResult) error
+}
+
+type debugService struct {
+	channelClient ChannelClient
+	ledgerClient  LedgerClient
+	ccid          string
+	logger        *zap.SugaredLogger
+}
+
+func NewDebugService(channelClient ChannelClient, ledgerClient LedgerClient, ccid string, logger *zap.SugaredLogger) DebugService {
+	return &debugService{
+		channelClient: channelClient,
+		ledgerClient:  ledgerClient,
+		ccid:          ccid,
+		logger:        logger.Named("debugservice"),
+	}
+}
+
+// TraceCall executes a call, taking the same arguments as eth_call, and
+// returns the steps of its execution. A call without a callee traces the
+// deployment of its data. The block parameter is ignored and the latest state
+// is always used.
+//
+// https://geth.ethereum.org/docs/rpc/ns-debug#debug_tracecall
+func (s *debugService) TraceCall(r *http.Request, args *types.EthArgs, reply *types.TraceResult) error {
+	if len(args.Transient) != 0 {
+		return fmt.Errorf("Transient data is not supported by debug_traceCall")
+	}
+
+	call, err := newMulticallCall(args)
+	if err != nil {
+		return err
+	}
+	if call.To == "" {
+		call.To = hex.EncodeToString(ZeroAddress)
+	}
+
+	return s.trace(call, nil, reply)
+}
+
+// TraceTransaction replays the call or deployment of a transaction, with the
+// callee, input, gas and value recorded in its block, on behalf of the creator
+// of the transaction, and returns the steps of its execution. Fabric does not
+// keep the state of past blocks, so the call runs on the latest state, not the
+// historical state the transaction ran on. Input sent in the transient map is
+// not recorded, so it cannot be replayed.
+//
+// https://geth.ethereum.org/docs/rpc/ns-debug#debug_tracetransaction
+func (s *debugService) TraceTransaction(r *http.Request, txID *string, reply *types.TraceResult) error {
+	strippedTxID := strip0x(*txID)
+	if strippedTxID == "" {
+		return fmt.Errorf("txID was empty")
+	}
+	s.logger.Debug("TraceTransaction", strippedTxID)
+
+	block, err := s.ledgerClient.QueryBlockByTxID(fab.TransactionID(strippedTxID))
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	_, txPayload, err := findTransaction(strippedTxID, block.GetData().GetData())
+	if err != nil {
+		return fmt.Errorf("Failed to parse through transactions in the block: %s", err)
+	}
+	if txPayload == nil {
+		return fmt.Errorf("Transaction %s was not found in block %d", strippedTxID, block.GetHeader().GetNumber())
+	}
+
+	args, _, err := getTransactionArgs(txPayload)
+	if err != nil {
+		return err
+	}
+	if !isEVMCall(args) {
+		return fmt.Errorf("Transaction %s is not a call or deployment of a contract", strippedTxID)
+	}
+
+	sigHdr := &common.SignatureHeader{}
+	if err := proto.Unmarshal(txPayload.GetHeader().GetSignatureHeader(), sigHdr); err != nil {
+		return fmt.Errorf("Failed unmarshaling signature header: %s", err)
+	}
+
+	call := multicallCall{To: string(args[0]), Input: string(args[1])}
+	if len(args) > 2 {
+		call.Gas = string(args[2])
+	}
+	if len(args) > 3 {
+		call.Value = string(args[3])
+	}
+	return s.trace(call, sigHdr.GetCreator(), reply)
+}
+
+// trace queries the trace function of the EVM chaincode, which takes a call
+// encoded as the calls of a multicall and, if it is not nil, the creator on
+// whose behalf the call is executed.
+func (s *debugService) trace(call multicallCall, creator []byte, reply *types.TraceResult) error {
+	callBytes, err := json.Marshal(call)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal call: %s", err)
+	}
+
+	args := [][]byte{callBytes}
+	if creator != nil {
+		args = append(args, creator)
+	}
+
+	response, err := s.channelClient.Query(channel.Request{
+		ChaincodeID: s.ccid,
+		Fcn:         "trace",
+		Args:        args,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	var result types.TraceResult
+	if err := json.Unmarshal(response.Payload, &result); err != nil {
+		return fmt.Errorf("Failed to unmarshal trace: %s", err)
+	}
+
+	*reply = result
+	return nil
+}
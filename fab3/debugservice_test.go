@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fab3_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric/protos/msp"
+
+	"github.com/hyperledger/fabric-chaincode-evm/fab3"
+	"github.com/hyperledger/fabric-chaincode-evm/fab3/types"
+
+	fab3_mocks "github.com/hyperledger/fabric-chaincode-evm/mocks/fab3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Debugservice", func() {
+	var (
+		debugservice fab3.DebugService
+
+		mockChClient     *fab3_mocks.MockChannelClient
+		mockLedgerClient *fab3_mocks.MockLedgerClient
+		sampleTrace      types.TraceResult
+		reply            types.TraceResult
+	)
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.AddSync(GinkgoWriter), zap.DebugLevel)
+	logger := zap.New(core).Sugar()
+
+	// traceArgs returns the call passed to the trace function of evmcc and
+	// the creator on whose behalf it is executed, if any
+	traceArgs := func() (map[string]string, []byte) {
+		Expect(mockChClient.QueryCallCount()).To(Equal(1))
+		request, _ := mockChClient.QueryArgsForCall(0)
+		Expect(request.ChaincodeID).To(Equal(evmcc))
+		Expect(request.Fcn).To(Equal("trace"))
+		Expect(len(request.Args)).To(BeNumerically("<=", 2))
+
+		var call map[string]string
+		Expect(json.Unmarshal(request.Args[0], &call)).To(Succeed())
+		if len(request.Args) == 2 {
+			return call, request.Args[1]
+		}
+		return call, nil
+	}
+
+	// traceCall returns the call passed to the trace function of evmcc
+	traceCall := func() map[string]string {
+		call, _ := traceArgs()
+		return call
+	}
+
+	BeforeEach(func() {
+		mockChClient = &fab3_mocks.MockChannelClient{}
+		mockLedgerClient = &fab3_mocks.MockLedgerClient{}
+		reply = types.TraceResult{}
+
+		sampleTrace = types.TraceResult{
+			Gas:         3,
+			ReturnValue: "2a",
+			StructLogs: []types.StructLog{
+				{PC: 0, Op: "PUSH1", Gas: 10, GasCost: 0, Depth: 1, Stack: []string{}, Memory: []string{}},
+				{PC: 2, Op: "SLOAD", Gas: 10, GasCost: 3, Depth: 1, Stack: []string{"00"}, Memory: []string{}, Storage: map[string]string{"00": "2a"}},
+			},
+		}
+		traceBytes, err := json.Marshal(sampleTrace)
+		Expect(err).ToNot(HaveOccurred())
+		mockChClient.QueryReturns(channel.Response{Payload: traceBytes}, nil)
+
+		debugservice = fab3.NewDebugService(mockChClient, mockLedgerClient, evmcc, logger)
+	})
+
+	Describe("TraceCall", func() {
+		It("traces the call with the trace function of evmcc", func() {
+			args := types.EthArgs{To: "0x1234", Data: "0x5678", Gas: "0x10", Value: "0x2"}
+			err := debugservice.TraceCall(&http.Request{}, &args, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal(sampleTrace))
+
+			call, creator := traceArgs()
+			Expect(call).To(Equal(map[string]string{"to": "1234", "input": "5678", "gas": "16", "value": "2"}))
+			Expect(creator).To(BeNil())
+		})
+
+		It("traces a deployment when the call has no callee", func() {
+			args := types.EthArgs{Data: "0x5678"}
+			err := debugservice.TraceCall(&http.Request{}, &args, &reply)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(traceCall()).To(Equal(map[string]string{"to": "0000000000000000000000000000000000000000", "input": "5678"}))
+		})
+
+		It("returns an error when the call has transient data", func() {
+			args := types.EthArgs{To: "0x1234", Transient: map[string]string{"input": "0x5678"}}
+			err := debugservice.TraceCall(&http.Request{}, &args, &reply)
+			Expect(err).To(MatchError("Transient data is not supported by debug_traceCall"))
+			Expect(mockChClient.QueryCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when the chaincode query fails", func() {
+			mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
+
+			args := types.EthArgs{To: "0x1234"}
+			err := debugservice.TraceCall(&http.Request{}, &args, &reply)
+			Expect(err).To(MatchError("Failed to query the ledger: boom!"))
+		})
+	})
+
+	Describe("TraceTransaction", func() {
+		var txID string
+
+		BeforeEach(func() {
+			txID = "1234567123"
+		})
+
+		It("replays the call recorded in the block of the transaction on behalf of its creator", func() {
+			tx, err := GetSampleTransaction([][]byte{[]byte("82373458"), []byte("5678"), []byte("50000"), []byte("100")}, []byte("sample-response"), []byte{}, txID)
+			Expect(err).ToNot(HaveOccurred())
+			mockLedgerClient.QueryBlockByTxIDReturns(GetSampleBlockWithTransaction(31, []byte("12345abcd"), tx), nil)
+
+			hash := "0x" + txID
+			err = debugservice.TraceTransaction(&http.Request{}, &hash, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal(sampleTrace))
+
+			Expect(mockLedgerClient.QueryBlockByTxIDCallCount()).To(Equal(1))
+			queriedTxID, _ := mockLedgerClient.QueryBlockByTxIDArgsForCall(0)
+			Expect(string(queriedTxID)).To(Equal(txID))
+			call, creator := traceArgs()
+			Expect(call).To(Equal(map[string]string{"to": "82373458", "input": "5678", "gas": "50000", "value": "100"}))
+			expectedCreator, err := proto.Marshal(&msp.SerializedIdentity{IdBytes: []byte(cert)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creator).To(Equal(expectedCreator))
+		})
+
+		It("returns an error when the transaction is not a call of a contract", func() {
+			tx, err := GetSampleTransaction([][]byte{[]byte("mint"), []byte("82373458"), []byte("100")}, []byte("100"), []byte{}, txID)
+			Expect(err).ToNot(HaveOccurred())
+			mockLedgerClient.QueryBlockByTxIDReturns(GetSampleBlockWithTransaction(31, []byte("12345abcd"), tx), nil)
+
+			err = debugservice.TraceTransaction(&http.Request{}, &txID, &reply)
+			Expect(err).To(MatchError("Transaction 1234567123 is not a call or deployment of a contract"))
+			Expect(mockChClient.QueryCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when the transaction is not in its block", func() {
+			tx, err := GetSampleTransaction([][]byte{[]byte("82373458"), []byte("5678")}, []byte("sample-response"), []byte{}, "another-tx")
+			Expect(err).ToNot(HaveOccurred())
+			mockLedgerClient.QueryBlockByTxIDReturns(GetSampleBlockWithTransaction(31, []byte("12345abcd"), tx), nil)
+
+			err = debugservice.TraceTransaction(&http.Request{}, &txID, &reply)
+			Expect(err).To(MatchError("Transaction 1234567123 was not found in block 31"))
+		})
+
+		It("returns an error when the ledger returns an error", func() {
+			mockLedgerClient.QueryBlockByTxIDReturns(nil, errors.New("bad ledger lookup"))
+
+			err := debugservice.TraceTransaction(&http.Request{}, &txID, &reply)
+			Expect(err).To(MatchError("Failed to query the ledger: bad ledger lookup"))
+		})
+
+		It("returns an error when given an empty transaction hash", func() {
+			hash := ""
+			err := debugservice.TraceTransaction(&http.Request{}, &hash, &reply)
+			Expect(err).To(MatchError("txID was empty"))
+		})
+	})
+})
@@ -20,11 +20,14 @@ import (
 	"sync"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/gorilla/rpc/v2/json2"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
@@ -37,6 +40,14 @@ import (
 
 var ZeroAddress = make([]byte, 20)
 
+// ExecutionRevertedCode is the JSON-RPC error code geth uses for transactions
+// reverted by the contract.
+const ExecutionRevertedCode json2.ErrorCode = 3
+
+// revertedStatus is the status of the error responses of the EVM chaincode for
+// the executions reverted by a contract.
+const revertedStatus = 422
+
 //go:generate counterfeiter -o ../mocks/fab3/mockchannelclient.go --fake-name MockChannelClient ./ ChannelClient
 
 type ChannelClient interface {
@@ -78,7 +89,10 @@ type EthService interface {
 	GetBlockByNumber(r *http.Request, p *[]interface{}, reply *types.Block) error
 	BlockNumber(r *http.Request, _ *interface{}, reply *string) error
 	GetTransactionByHash(r *http.Request, txID *string, reply *types.Transaction) error
-	GetTransactionCount(r *http.Request, _ *interface{}, reply *string) error
+	GetTransactionCount(r *http.Request, p *[]string, reply *string) error
+	GetStorageAt(r *http.Request, p *[]string, reply *string) error
+	GetAccount(r *http.Request, p *[]string, reply *types.Account) error
+	Multicall(r *http.Request, args *[]types.EthArgs, reply *[]types.CallResult) error
 	GetLogs(*http.Request, *types.GetLogsArgs, *[]types.Log) error
 	NewFilter(*http.Request, *types.GetLogsArgs, *string) error
 	UninstallFilter(*http.Request, *string, *bool) error
@@ -121,9 +135,27 @@ func (s *ethService) GetCode(r *http.Request, arg *string, reply *string) error
 }
 
 func (s *ethService) Call(r *http.Request, args *types.EthArgs, reply *string) error {
-	response, err := s.query(s.ccid, strip0x(args.To), [][]byte{[]byte(strip0x(args.Data))})
+	ccArgs, err := evmArgs(args)
+	if err != nil {
+		return err
+	}
 
+	transient, err := transientMap(args)
 	if err != nil {
+		return err
+	}
+
+	response, err := s.channelClient.Query(channel.Request{
+		ChaincodeID:  s.ccid,
+		Fcn:          strip0x(args.To),
+		Args:         ccArgs,
+		TransientMap: transient,
+	})
+
+	if err != nil {
+		if revertErr := revertError(err); revertErr != nil {
+			return revertErr
+		}
 		return fmt.Errorf("Failed to query the ledger: %s", err)
 	}
 
@@ -138,13 +170,27 @@ func (s *ethService) SendTransaction(r *http.Request, args *types.EthArgs, reply
 		args.To = hex.EncodeToString(ZeroAddress)
 	}
 
+	ccArgs, err := evmArgs(args)
+	if err != nil {
+		return err
+	}
+
+	transient, err := transientMap(args)
+	if err != nil {
+		return err
+	}
+
 	response, err := s.channelClient.Execute(channel.Request{
-		ChaincodeID: s.ccid,
-		Fcn:         strip0x(args.To),
-		Args:        [][]byte{[]byte(strip0x(args.Data))},
+		ChaincodeID:  s.ccid,
+		Fcn:          strip0x(args.To),
+		Args:         ccArgs,
+		TransientMap: transient,
 	})
 
 	if err != nil {
+		if revertErr := revertError(err); revertErr != nil {
+			return revertErr
+		}
 		return fmt.Errorf("Failed to execute transaction: %s", err)
 	}
 	*reply = string(response.TransactionID)
@@ -163,11 +209,9 @@ func (s *ethService) GetTransactionReceipt(r *http.Request, txID *string, reply
 	blkHeader := block.GetHeader()
 	transactionsFilter := util.TxValidationFlags(block.GetMetadata().GetMetadata()[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
 	receipt := types.TxReceipt{
-		TransactionHash:   "0x" + strippedTxID,
-		BlockHash:         "0x" + hex.EncodeToString(blockHash(blkHeader)),
-		BlockNumber:       "0x" + strconv.FormatUint(blkHeader.GetNumber(), 16),
-		GasUsed:           0,
-		CumulativeGasUsed: 0,
+		TransactionHash: "0x" + strippedTxID,
+		BlockHash:       "0x" + hex.EncodeToString(blockHash(blkHeader)),
+		BlockNumber:     "0x" + strconv.FormatUint(blkHeader.GetNumber(), 16),
 	}
 
 	index, txPayload, err := findTransaction(strippedTxID, block.GetData().GetData())
@@ -190,6 +234,15 @@ func (s *ethService) GetTransactionReceipt(r *http.Request, txID *string, reply
 		return fmt.Errorf("Failed getting transaction infomration: %s", err)
 	}
 
+	cumulativeGasUsed, err := blockGasUsed(s.ccid, block.GetData().GetData()[:indexU+1], transactionsFilter)
+	if err != nil {
+		return fmt.Errorf("Failed getting the gas used in the block: %s", err)
+	}
+
+	receipt.CumulativeGasUsed = int(cumulativeGasUsed)
+	if txnValidValue == 1 {
+		receipt.GasUsed = int(gasUsed(s.ccid, respPayload))
+	}
 	receipt.From = from
 	if to != "" {
 		callee, err := hex.DecodeString(to)
@@ -227,27 +280,75 @@ func (s *ethService) Accounts(r *http.Request, arg *string, reply *[]string) err
 }
 
 // EstimateGas accepts the same arguments as Call but all arguments are
-// optional.  This implementation ignores all arguments and returns a zero
-// estimate.
+// optional.
 //
 // The intention is to estimate how much gas is necessary to allow a transaction
-// to complete.
-//
-// EVM-chaincode does not require gas to run transactions. The chaincode will
-// give enough gas per transaction.
-func (s *ethService) EstimateGas(r *http.Request, _ *types.EthArgs, reply *string) error {
+// to complete. The transaction is simulated by querying the EVM chaincode, the
+// estimate is the gas the chaincode reports the transaction used. Omitting the
+// `to` field estimates a contract deployment.
+func (s *ethService) EstimateGas(r *http.Request, args *types.EthArgs, reply *string) error {
 	s.logger.Debug("EstimateGas called")
-	*reply = "0x0"
+
+	to := strip0x(args.To)
+	if to == "" {
+		to = hex.EncodeToString(ZeroAddress)
+	}
+
+	ccArgs, err := evmArgs(args)
+	if err != nil {
+		return err
+	}
+
+	transient, err := transientMap(args)
+	if err != nil {
+		return err
+	}
+
+	response, err := s.channelClient.Query(channel.Request{
+		ChaincodeID:  s.ccid,
+		Fcn:          to,
+		Args:         ccArgs,
+		TransientMap: transient,
+	})
+	if err != nil {
+		if revertErr := revertError(err); revertErr != nil {
+			return revertErr
+		}
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	var used uint64
+	if len(response.Responses) > 0 {
+		used = parseGasUsed(response.Responses[0].GetResponse().GetMessage())
+	}
+
+	*reply = "0x" + strconv.FormatUint(used, 16)
 	return nil
 }
 
 // GetBalance takes an address and a block, but this implementation
-// does not check or use either parameter.
+// does not check or use the block parameter.
 //
-// Always returns zero.
+// Returns the native balance of the address, which is zero unless balance has
+// been minted to it.
 func (s *ethService) GetBalance(r *http.Request, p *[]string, reply *string) error {
 	s.logger.Debug("GetBalance called")
-	*reply = "0x0"
+	params := *p
+	if len(params) == 0 {
+		return fmt.Errorf("need at least 1 param, got 0")
+	}
+
+	response, err := s.query(s.ccid, "getBalance", [][]byte{[]byte(strip0x(params[0]))})
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	balance, err := strconv.ParseUint(string(response.Payload), 10, 64)
+	if err != nil {
+		return fmt.Errorf("Failed to parse balance: %s", err)
+	}
+
+	*reply = "0x" + strconv.FormatUint(balance, 16)
 	return nil
 }
 
@@ -261,7 +362,7 @@ func (s *ethService) GetBlockByNumber(r *http.Request, p *[]interface{}, reply *
 	// must have two params
 	numParams := len(params)
 	if numParams != 2 {
-		return fmt.Errorf("need 2 params, got %q", numParams)
+		return fmt.Errorf("need 2 params, got %d", numParams)
 	}
 	// first arg is string of block to get
 	number, ok := params[0].(string)
@@ -294,6 +395,11 @@ func (s *ethService) GetBlockByNumber(r *http.Request, p *[]interface{}, reply *
 	// each data is a txn
 	data := block.GetData().GetData()
 	transactionsFilter := util.TxValidationFlags(block.GetMetadata().GetMetadata()[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	totalGasUsed, err := blockGasUsed(s.ccid, data, transactionsFilter)
+	if err != nil {
+		return err
+	}
+
 	txns := make([]interface{}, 0, len(data))
 
 	// drill into the block to find the transaction ids it contains
@@ -337,6 +443,7 @@ func (s *ethService) GetBlockByNumber(r *http.Request, p *[]interface{}, reply *
 			Number:     blockNumber,
 			Hash:       blockHash,
 			ParentHash: "0x" + hex.EncodeToString(blkHeader.GetPreviousHash()),
+			GasUsed:    "0x" + strconv.FormatUint(totalGasUsed, 16),
 		},
 		Transactions: txns,
 	}
@@ -411,12 +518,150 @@ func (s *ethService) GetTransactionByHash(r *http.Request, txID *string, reply *
 	return nil
 }
 
-// GetTransactionCount will always return 0 regardless of the provided parameters.
-//
-// This implmentation does not match the Ethereum JSON RPC API definition of eth_getTransactionCount.
-// This has been stubbed out in order to have Truffle IDE support.
-func (s *ethService) GetTransactionCount(r *http.Request, _ *interface{}, reply *string) error {
-	*reply = "0x0"
+// GetTransactionCount returns the nonce of the provided address, which is the
+// number of transactions sent from the address that modified the state of the
+// EVM. The block parameter is ignored and the latest state is always used.
+func (s *ethService) GetTransactionCount(r *http.Request, p *[]string, reply *string) error {
+	s.logger.Debug("GetTransactionCount called")
+	params := *p
+	if len(params) == 0 {
+		return fmt.Errorf("need at least 1 param, got 0")
+	}
+
+	response, err := s.query(s.ccid, "getNonce", [][]byte{[]byte(strip0x(params[0]))})
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	nonce, err := strconv.ParseUint(string(response.Payload), 10, 64)
+	if err != nil {
+		return fmt.Errorf("Failed to parse nonce: %s", err)
+	}
+
+	*reply = "0x" + strconv.FormatUint(nonce, 16)
+	return nil
+}
+
+// GetStorageAt returns the 32 byte word stored at a position in the storage of
+// the provided address. The block parameter is ignored and the storage at the
+// latest block is always returned.
+func (s *ethService) GetStorageAt(r *http.Request, p *[]string, reply *string) error {
+	s.logger.Debug("GetStorageAt called")
+	params := *p
+	if len(params) < 2 {
+		return fmt.Errorf("need at least 2 params, got %d", len(params))
+	}
+
+	response, err := s.query(s.ccid, "getStorageAt", [][]byte{[]byte(strip0x(params[0])), []byte(strip0x(params[1]))})
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	*reply = "0x" + string(response.Payload)
+	return nil
+}
+
+// GetAccount is an extension to the ethereum json-rpc which describes the
+// account stored under the provided address: its balance, nonce, the size and
+// hash of its code and its permission flags. It takes an address and a block,
+// the block parameter is ignored and the latest state is always used.
+func (s *ethService) GetAccount(r *http.Request, p *[]string, reply *types.Account) error {
+	s.logger.Debug("GetAccount called")
+	params := *p
+	if len(params) == 0 {
+		return fmt.Errorf("need at least 1 param, got 0")
+	}
+
+	response, err := s.query(s.ccid, "getAccount", [][]byte{[]byte(strip0x(params[0]))})
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	acct := struct {
+		Address     string
+		Exists      bool
+		Balance     uint64
+		Sequence    uint64
+		CodeSize    uint64
+		CodeHash    string
+		Permissions []string
+	}{}
+	if err := json.Unmarshal(response.Payload, &acct); err != nil {
+		return fmt.Errorf("Failed to unmarshal account: %s", err)
+	}
+
+	*reply = types.Account{
+		Address:     "0x" + acct.Address,
+		Exists:      acct.Exists,
+		Balance:     "0x" + strconv.FormatUint(acct.Balance, 16),
+		Sequence:    "0x" + strconv.FormatUint(acct.Sequence, 16),
+		CodeSize:    "0x" + strconv.FormatUint(acct.CodeSize, 16),
+		Permissions: acct.Permissions,
+	}
+	if acct.CodeHash != "" {
+		reply.CodeHash = "0x" + acct.CodeHash
+	}
+	return nil
+}
+
+// Multicall is an extension to the ethereum json-rpc which runs a list of
+// calls, taking the same arguments as eth_call, in a single query of the EVM
+// chaincode. A failed call does not fail the others, its error is returned in
+// its result instead. Calls whose arguments are malformed fail without being
+// queried.
+func (s *ethService) Multicall(r *http.Request, args *[]types.EthArgs, reply *[]types.CallResult) error {
+	callResults := make([]types.CallResult, len(*args))
+	var (
+		indexes []int
+		calls   []multicallCall
+	)
+	for i := range *args {
+		call, err := newMulticallCall(&(*args)[i])
+		if err != nil {
+			callResults[i] = types.CallResult{Error: err.Error()}
+			continue
+		}
+		indexes = append(indexes, i)
+		calls = append(calls, call)
+	}
+
+	if len(calls) == 0 {
+		*reply = callResults
+		return nil
+	}
+
+	callsBytes, err := json.Marshal(calls)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal calls: %s", err)
+	}
+
+	response, err := s.query(s.ccid, "multicall", [][]byte{callsBytes})
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	var results []struct {
+		Output   string
+		Error    string
+		Reverted bool
+	}
+	if err := json.Unmarshal(response.Payload, &results); err != nil {
+		return fmt.Errorf("Failed to unmarshal results: %s", err)
+	}
+
+	if len(results) != len(calls) {
+		return fmt.Errorf("Received %d results for %d calls", len(results), len(calls))
+	}
+
+	for j, result := range results {
+		callResults[indexes[j]] = types.CallResult{
+			Success:    result.Error == "",
+			ReturnData: "0x" + result.Output,
+			Error:      result.Error,
+			Reverted:   result.Reverted,
+		}
+	}
+	*reply = callResults
 	return nil
 }
 
@@ -589,6 +834,135 @@ func (s *ethService) parseBlockNum(input string) (uint64, error) {
 	}
 }
 
+// evmArgs returns the arguments passed to the EVM chaincode along with the
+// callee address: the input data and, if provided, the gas and the value of
+// the transaction. The gas and value are converted from hex quantities to the
+// decimal values evmcc expects, a zero gas leaves the gas limit of the
+// chaincode in place.
+func evmArgs(args *types.EthArgs) ([][]byte, error) {
+	gas, err := parseQuantity(args.Gas)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse gas: %s", err)
+	}
+
+	value, err := parseQuantity(args.Value)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse value: %s", err)
+	}
+
+	ccArgs := [][]byte{[]byte(strip0x(args.Data))}
+	switch {
+	case value != 0:
+		var gasArg []byte
+		if gas != 0 {
+			gasArg = []byte(strconv.FormatUint(gas, 10))
+		}
+		ccArgs = append(ccArgs, gasArg, []byte(strconv.FormatUint(value, 10)))
+	case gas != 0:
+		ccArgs = append(ccArgs, []byte(strconv.FormatUint(gas, 10)))
+	}
+
+	return ccArgs, nil
+}
+
+// transientMap returns the transient map of the proposal of a transaction,
+// which holds the input data of the Transient extension field under the keys
+// the EVM chaincode reads it from. It is nil when the field is not set.
+func transientMap(args *types.EthArgs) (map[string][]byte, error) {
+	if len(args.Transient) == 0 {
+		return nil, nil
+	}
+
+	transient := make(map[string][]byte, len(args.Transient))
+	for key, data := range args.Transient {
+		if key == "input" {
+			transient["evmcc:input"] = []byte(strip0x(data))
+			continue
+		}
+
+		if _, err := strconv.ParseUint(key, 10, 32); err != nil {
+			return nil, fmt.Errorf("Invalid transient key %s: must be input or a decimal byte offset", key)
+		}
+		transient["evmcc:input:"+key] = []byte(strip0x(data))
+	}
+	return transient, nil
+}
+
+// multicallCall is a call of the multicall query of the EVM chaincode, which
+// has the fields of the arguments of a single transaction.
+type multicallCall struct {
+	To    string `json:"to"`
+	Input string `json:"input"`
+	Gas   string `json:"gas,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+func newMulticallCall(args *types.EthArgs) (multicallCall, error) {
+	if len(args.Transient) != 0 {
+		return multicallCall{}, fmt.Errorf("Transient data is not supported by multicall")
+	}
+
+	gas, err := parseQuantity(args.Gas)
+	if err != nil {
+		return multicallCall{}, fmt.Errorf("Failed to parse gas: %s", err)
+	}
+
+	value, err := parseQuantity(args.Value)
+	if err != nil {
+		return multicallCall{}, fmt.Errorf("Failed to parse value: %s", err)
+	}
+
+	call := multicallCall{To: strip0x(args.To), Input: strip0x(args.Data)}
+	if gas != 0 {
+		call.Gas = strconv.FormatUint(gas, 10)
+	}
+	if value != 0 {
+		call.Value = strconv.FormatUint(value, 10)
+	}
+	return call, nil
+}
+
+// parseQuantity parses a hex encoded quantity, an empty quantity is zero.
+func parseQuantity(quantity string) (uint64, error) {
+	if quantity == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(strip0x(quantity), 16, 64)
+}
+
+// revertError returns the JSON-RPC error for a transaction reverted by the
+// contract, which the EVM chaincode reports with the revertedStatus. Like geth,
+// the message is the reason the chaincode decoded and the data is the hex
+// encoded revert data, which the chaincode returns as the payload of its error
+// response. It returns nil for any other error.
+func revertError(err error) *json2.Error {
+	if errs, ok := errors.Cause(err).(multi.Errors); ok {
+		for _, e := range errs {
+			if revertErr := revertError(e); revertErr != nil {
+				return revertErr
+			}
+		}
+		return nil
+	}
+
+	s, ok := status.FromError(err)
+	if !ok || s.Group != status.EndorserServerStatus || s.Code != revertedStatus {
+		return nil
+	}
+
+	// The details of an endorser error are the endorser and the payload
+	var data []byte
+	if len(s.Details) > 1 {
+		data, _ = s.Details[1].([]byte)
+	}
+
+	return &json2.Error{
+		Code:    ExecutionRevertedCode,
+		Message: s.Message,
+		Data:    "0x" + hex.EncodeToString(data),
+	}
+}
+
 func strip0x(addr string) string {
 	//Not checking for malformed addresses just stripping `0x` prefix where applicable
 	return strings.TrimPrefix(addr, "0x")
@@ -633,27 +1007,12 @@ func getPayloads(txActions *peer.TransactionAction) (*peer.ChaincodeProposalPayl
 // getTransactionInformation takes a payload
 // It returns if available the To, Input, From, the Response Payload of the transaction in the payload, otherwise it returns an error
 func getTransactionInformation(payload *common.Payload) (string, string, string, *peer.ChaincodeAction, error) {
-	txActions := &peer.Transaction{}
-	err := proto.Unmarshal(payload.GetData(), txActions)
+	args, respPayload, err := getTransactionArgs(payload)
 	if err != nil {
 		return "", "", "", nil, err
 	}
 
-	ccPropPayload, respPayload, err := getPayloads(txActions.GetActions()[0])
-	if err != nil {
-		return "", "", "", nil, fmt.Errorf("Failed to unmarshal transaction: %s", err)
-	}
-
-	invokeSpec := &peer.ChaincodeInvocationSpec{}
-	err = proto.Unmarshal(ccPropPayload.GetInput(), invokeSpec)
-	if err != nil {
-		return "", "", "", nil, fmt.Errorf("Failed to unmarshal transaction: %s", err)
-	}
-
-	// callee, input data is standard case, also handle getcode & account cases
-	args := invokeSpec.GetChaincodeSpec().GetInput().Args
-
-	if len(args) != 2 || string(args[0]) == "getCode" {
+	if !isEVMCall(args) {
 		// no more data available to fill the transaction
 		return "", "", "", respPayload, nil
 	}
@@ -676,6 +1035,98 @@ func getTransactionInformation(payload *common.Payload) (string, string, string,
 	return string(args[0]), string(args[1]), "0x" + hex.EncodeToString(from), respPayload, nil
 }
 
+// getTransactionArgs takes a payload
+// It returns the arguments of the chaincode invocation and the Response Payload of the transaction in the payload, otherwise it returns an error
+func getTransactionArgs(payload *common.Payload) ([][]byte, *peer.ChaincodeAction, error) {
+	txActions := &peer.Transaction{}
+	err := proto.Unmarshal(payload.GetData(), txActions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ccPropPayload, respPayload, err := getPayloads(txActions.GetActions()[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to unmarshal transaction: %s", err)
+	}
+
+	invokeSpec := &peer.ChaincodeInvocationSpec{}
+	err = proto.Unmarshal(ccPropPayload.GetInput(), invokeSpec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to unmarshal transaction: %s", err)
+	}
+
+	return invokeSpec.GetChaincodeSpec().GetInput().Args, respPayload, nil
+}
+
+// isEVMCall returns whether the arguments of an evmcc transaction are those of
+// a call or a deployment: the callee and the input data, optionally followed
+// by the gas and value. The functions of the chaincode, such as getCode,
+// getBalance, mint and burn, are not calls.
+func isEVMCall(args [][]byte) bool {
+	return len(args) >= 2 && len(args) <= 4 && isEVMTransaction(string(args[0]))
+}
+
+// gasUsed returns the gas used by a transaction, which the EVM chaincode
+// reports as a decimal string in the message of its response. Transactions
+// without a gas report, such as queries of the code of a contract, used no
+// gas. Transactions of other chaincodes than the EVM chaincode ccid used no
+// gas either, whatever the message of their response.
+func gasUsed(ccid string, respPayload *peer.ChaincodeAction) uint64 {
+	if respPayload.GetChaincodeId().GetName() != ccid {
+		return 0
+	}
+	return parseGasUsed(respPayload.GetResponse().GetMessage())
+}
+
+func parseGasUsed(message string) uint64 {
+	used, err := strconv.ParseUint(message, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return used
+}
+
+// blockGasUsed takes in block data from block.GetData().GetData() and returns
+// the gas used by its valid endorser transactions of the EVM chaincode ccid.
+// Invalid transactions had no effect on the ledger, so they do not count
+// towards the gas used.
+func blockGasUsed(ccid string, blockData [][]byte, transactionsFilter util.TxValidationFlags) (uint64, error) {
+	var total uint64
+	for index, transactionData := range blockData {
+		if transactionData == nil || !transactionsFilter.IsValid(index) {
+			continue
+		}
+
+		payload, chdr, err := getChannelHeaderandPayloadFromTransactionData(transactionData)
+		if err != nil {
+			return 0, err
+		}
+
+		if common.HeaderType(chdr.GetType()) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+
+		_, _, _, respPayload, err := getTransactionInformation(payload)
+		if err != nil {
+			return 0, err
+		}
+
+		total += gasUsed(ccid, respPayload)
+	}
+	return total, nil
+}
+
+// isEVMTransaction returns whether the first argument of an evmcc transaction
+// is a callee address rather than one of the functions of the chaincode.
+func isEVMTransaction(firstArg string) bool {
+	switch firstArg {
+	case "getCode", "getBalance", "getNonce", "mint", "burn", "setDeployers", "getACL", "setACL",
+		"pause", "unpause", "upgrade", "getStorageAt", "getAccount", "batch", "multicall", "trace":
+		return false
+	}
+	return true
+}
+
 // findTransaction takes in the txId and  block data from block.GetData().GetData() where block is of type *common.Block
 // It returns the index of the transaction, transaction payload, otherwise it returns an error
 func findTransaction(txID string, blockData [][]byte) (string, *common.Payload, error) {
@@ -713,11 +1164,17 @@ func fabricEventToEVMLogs(logger *zap.SugaredLogger, events []byte, blocknumber,
 		return nil, errors.Wrap(err, "failed to decode chaincode event")
 	}
 
-	var eventMsgs []event.Event
-	err = json.Unmarshal(chaincodeEvent.Payload, &eventMsgs)
+	// Audit events of administrative operations carry no EVM logs
+	if strings.HasPrefix(chaincodeEvent.EventName, event.AuditEventPrefix) {
+		return nil, nil
+	}
+
+	var payload event.Payload
+	err = json.Unmarshal(chaincodeEvent.Payload, &payload)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal chaincode event payload")
 	}
+	eventMsgs := payload.Logs
 
 	var txLogs []types.Log
 LOG_EVENT:
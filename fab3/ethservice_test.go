@@ -19,13 +19,17 @@ import (
 	"strconv"
 	"strings"
 
+	pkgerrors "github.com/pkg/errors"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/gorilla/rpc/v2/json2"
 	"github.com/hyperledger/burrow/crypto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
@@ -194,6 +198,28 @@ var _ = Describe("Ethservice", func() {
 			Expect(reply).To(Equal("0x" + string(encodedResponse)))
 		})
 
+		Context("when transient data is provided", func() {
+			BeforeEach(func() {
+				sampleArgs.Data = ""
+				sampleArgs.Transient = map[string]string{"input": "0x6d4ce63c"}
+			})
+
+			It("sends the data in the transient map of the proposal", func() {
+				var reply string
+				err := ethservice.Call(&http.Request{}, sampleArgs, &reply)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockChClient.QueryCallCount()).To(Equal(1))
+				chReq, _ := mockChClient.QueryArgsForCall(0)
+				Expect(chReq).To(Equal(channel.Request{
+					ChaincodeID:  evmcc,
+					Fcn:          sampleArgs.To,
+					Args:         [][]byte{[]byte("")},
+					TransientMap: map[string][]byte{"evmcc:input": []byte("6d4ce63c")},
+				}))
+			})
+		})
+
 		Context("when the ledger errors when processing a query", func() {
 			BeforeEach(func() {
 				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
@@ -208,6 +234,96 @@ var _ = Describe("Ethservice", func() {
 			})
 		})
 
+		Context("when the contract reverts", func() {
+			var revertErr error
+
+			BeforeEach(func() {
+				revertErr = status.New(status.EndorserServerStatus, 422,
+					"execution reverted: nope", []interface{}{"peer0", []byte{0xde, 0xad}})
+				mockChClient.QueryReturns(channel.Response{}, revertErr)
+			})
+
+			It("returns a JSON-RPC error with the revert reason and data", func() {
+				var reply string
+
+				err := ethservice.Call(&http.Request{}, sampleArgs, &reply)
+				Expect(err).To(Equal(&json2.Error{
+					Code:    fab3.ExecutionRevertedCode,
+					Message: "execution reverted: nope",
+					Data:    "0xdead",
+				}))
+				Expect(reply).To(BeEmpty())
+			})
+
+			Context("when every endorser returns the revert", func() {
+				BeforeEach(func() {
+					mockChClient.QueryReturns(channel.Response{}, pkgerrors.Wrap(multi.Errors{errors.New("timeout"), revertErr}, "query failed"))
+				})
+
+				It("returns a JSON-RPC error with the revert reason and data", func() {
+					var reply string
+
+					err := ethservice.Call(&http.Request{}, sampleArgs, &reply)
+					Expect(err).To(Equal(&json2.Error{
+						Code:    fab3.ExecutionRevertedCode,
+						Message: "execution reverted: nope",
+						Data:    "0xdead",
+					}))
+				})
+			})
+
+			Context("when the endorser error is not a revert", func() {
+				BeforeEach(func() {
+					mockChClient.QueryReturns(channel.Response{}, status.New(status.EndorserServerStatus, 500,
+						"failed to execute contract: insufficient gas", []interface{}{"peer0", []byte{}}))
+				})
+
+				It("returns a corresponding error", func() {
+					var reply string
+
+					err := ethservice.Call(&http.Request{}, sampleArgs, &reply)
+					Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+					Expect(err).To(MatchError(ContainSubstring("insufficient gas")))
+				})
+			})
+
+			Context("when the message of an endorser error which is not a revert mentions a revert", func() {
+				BeforeEach(func() {
+					mockChClient.QueryReturns(channel.Response{}, status.New(status.EndorserServerStatus, 500,
+						"failed to invoke chaincode: execution reverted: nope", []interface{}{"peer0", []byte{0xde, 0xad}}))
+				})
+
+				It("does not return a revert error", func() {
+					var reply string
+
+					err := ethservice.Call(&http.Request{}, sampleArgs, &reply)
+					Expect(err).ToNot(BeAssignableToTypeOf(&json2.Error{}))
+					Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+				})
+			})
+		})
+
+		Context("when the gas is provided", func() {
+			BeforeEach(func() {
+				sampleArgs.Gas = "0x2710"
+			})
+
+			It("passes the gas to the evmcc as a decimal value", func() {
+				var reply string
+
+				err := ethservice.Call(&http.Request{}, sampleArgs, &reply)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockChClient.QueryCallCount()).To(Equal(1))
+				chReq, _ := mockChClient.QueryArgsForCall(0)
+				Expect(chReq).To(Equal(channel.Request{
+					ChaincodeID: evmcc,
+					Fcn:         sampleArgs.To,
+					Args:        [][]byte{[]byte(sampleArgs.Data), []byte("10000")},
+				}))
+			})
+		})
+
 		Context("when the address has a `0x` prefix", func() {
 			BeforeEach(func() {
 				sampleArgs.To = "0x" + sampleArgs.To
@@ -295,6 +411,152 @@ var _ = Describe("Ethservice", func() {
 			Expect(reply).To(Equal(string(sampleResponse.TransactionID)))
 		})
 
+		Context("when transient data is provided", func() {
+			BeforeEach(func() {
+				sampleArgs.Data = "0x60fe47b1"
+				sampleArgs.Transient = map[string]string{"4": "0x000000000000000000000000000000000000000000000000000000000000002a"}
+			})
+
+			It("sends the parts of the input in the transient map of the proposal", func() {
+				var reply string
+				err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockChClient.ExecuteCallCount()).To(Equal(1))
+				chReq, _ := mockChClient.ExecuteArgsForCall(0)
+				Expect(chReq).To(Equal(channel.Request{
+					ChaincodeID: evmcc,
+					Fcn:         sampleArgs.To,
+					Args:        [][]byte{[]byte("60fe47b1")},
+					TransientMap: map[string][]byte{
+						"evmcc:input:4": []byte("000000000000000000000000000000000000000000000000000000000000002a"),
+					},
+				}))
+			})
+
+			Context("when a key is neither input nor an offset", func() {
+				BeforeEach(func() {
+					sampleArgs.Transient = map[string]string{"secret": "0x2a"}
+				})
+
+				It("returns an error", func() {
+					var reply string
+					err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+					Expect(err).To(MatchError("Invalid transient key secret: must be input or a decimal byte offset"))
+					Expect(mockChClient.ExecuteCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the gas is provided", func() {
+			BeforeEach(func() {
+				sampleArgs.Gas = "0xc350"
+			})
+
+			It("passes the gas to the evmcc as a decimal value", func() {
+				var reply string
+				err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockChClient.ExecuteCallCount()).To(Equal(1))
+				chReq, _ := mockChClient.ExecuteArgsForCall(0)
+				Expect(chReq).To(Equal(channel.Request{
+					ChaincodeID: evmcc,
+					Fcn:         sampleArgs.To,
+					Args:        [][]byte{[]byte(sampleArgs.Data), []byte("50000")},
+				}))
+			})
+
+			Context("when the gas is zero", func() {
+				BeforeEach(func() {
+					sampleArgs.Gas = "0x0"
+				})
+
+				It("does not pass the gas to the evmcc", func() {
+					var reply string
+					err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(mockChClient.ExecuteCallCount()).To(Equal(1))
+					chReq, _ := mockChClient.ExecuteArgsForCall(0)
+					Expect(chReq.Args).To(Equal([][]byte{[]byte(sampleArgs.Data)}))
+				})
+			})
+
+			Context("when the gas is malformed", func() {
+				BeforeEach(func() {
+					sampleArgs.Gas = "0xlots"
+				})
+
+				It("returns an error", func() {
+					var reply string
+					err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+					Expect(err).To(MatchError(ContainSubstring("Failed to parse gas")))
+					Expect(mockChClient.ExecuteCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when a value is provided", func() {
+			BeforeEach(func() {
+				sampleArgs.Value = "0x64"
+			})
+
+			It("passes an empty gas and the value to the evmcc as a decimal value", func() {
+				var reply string
+				err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockChClient.ExecuteCallCount()).To(Equal(1))
+				chReq, _ := mockChClient.ExecuteArgsForCall(0)
+				Expect(chReq.Args).To(Equal([][]byte{[]byte(sampleArgs.Data), nil, []byte("100")}))
+			})
+
+			Context("when the gas is provided as well", func() {
+				BeforeEach(func() {
+					sampleArgs.Gas = "0xc350"
+				})
+
+				It("passes the gas and the value to the evmcc", func() {
+					var reply string
+					err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(mockChClient.ExecuteCallCount()).To(Equal(1))
+					chReq, _ := mockChClient.ExecuteArgsForCall(0)
+					Expect(chReq.Args).To(Equal([][]byte{[]byte(sampleArgs.Data), []byte("50000"), []byte("100")}))
+				})
+			})
+
+			Context("when the value is zero", func() {
+				BeforeEach(func() {
+					sampleArgs.Value = "0x0"
+				})
+
+				It("does not pass the value to the evmcc", func() {
+					var reply string
+					err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+					Expect(err).ToNot(HaveOccurred())
+
+					chReq, _ := mockChClient.ExecuteArgsForCall(0)
+					Expect(chReq.Args).To(Equal([][]byte{[]byte(sampleArgs.Data)}))
+				})
+			})
+
+			Context("when the value is malformed", func() {
+				BeforeEach(func() {
+					sampleArgs.Value = "0xlots"
+				})
+
+				It("returns an error", func() {
+					var reply string
+					err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+					Expect(err).To(MatchError(ContainSubstring("Failed to parse value")))
+					Expect(mockChClient.ExecuteCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("when the transaction is a contract deployment", func() {
 			BeforeEach(func() {
 				sampleArgs.To = ""
@@ -334,6 +596,25 @@ var _ = Describe("Ethservice", func() {
 			})
 		})
 
+		Context("when the contract reverts", func() {
+			BeforeEach(func() {
+				mockChClient.ExecuteReturns(channel.Response{}, status.New(status.EndorserServerStatus, 422,
+					"execution reverted", []interface{}{"peer0", []byte{0xde, 0xad}}))
+			})
+
+			It("returns a JSON-RPC error with the revert data", func() {
+				var reply string
+
+				err := ethservice.SendTransaction(&http.Request{}, sampleArgs, &reply)
+				Expect(err).To(Equal(&json2.Error{
+					Code:    fab3.ExecutionRevertedCode,
+					Message: "execution reverted",
+					Data:    "0xdead",
+				}))
+				Expect(reply).To(BeEmpty())
+			})
+		})
+
 		Context("when the address has a `0x` prefix", func() {
 			BeforeEach(func() {
 				sampleArgs.To = "0x" + sampleArgs.To
@@ -432,6 +713,100 @@ var _ = Describe("Ethservice", func() {
 			}))
 		})
 
+		Context("when the transactions in the block report the gas they used", func() {
+			BeforeEach(func() {
+				var err error
+				sampleTransaction, err = GetSampleTransactionWithGasUsed([][]byte{[]byte(sampleAddress), []byte("sample arg 2")}, []byte("sample-response"), []byte{}, sampleTransactionID, "250")
+				Expect(err).ToNot(HaveOccurred())
+
+				otherTransaction, err = GetSampleTransactionWithGasUsed([][]byte{[]byte("1234567"), []byte("sample arg 3")}, []byte("sample-response 2"), []byte{}, "5678", "100")
+				Expect(err).ToNot(HaveOccurred())
+
+				sampleBlock = GetSampleBlockWithTransaction(31, []byte("12345abcd"), otherTransaction, sampleTransaction)
+				mockLedgerClient.QueryBlockByTxIDReturns(sampleBlock, nil)
+			})
+
+			It("returns the gas used by the transaction and the gas used in the block up to it", func() {
+				var reply types.TxReceipt
+
+				err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply.GasUsed).To(Equal(250))
+				Expect(reply.CumulativeGasUsed).To(Equal(350))
+			})
+
+			Context("when an earlier transaction in the block is invalid", func() {
+				BeforeEach(func() {
+					sampleBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER][0] = byte(peer.TxValidationCode_MVCC_READ_CONFLICT)
+				})
+
+				It("does not count the gas of the invalid transaction", func() {
+					var reply types.TxReceipt
+
+					err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(reply.GasUsed).To(Equal(250))
+					Expect(reply.CumulativeGasUsed).To(Equal(250))
+				})
+			})
+
+			Context("when the transaction is invalid", func() {
+				BeforeEach(func() {
+					sampleBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER][1] = byte(peer.TxValidationCode_MVCC_READ_CONFLICT)
+				})
+
+				It("reports that no gas was used by the transaction", func() {
+					var reply types.TxReceipt
+
+					err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(reply.Status).To(Equal("0x0"))
+					Expect(reply.GasUsed).To(Equal(0))
+					Expect(reply.CumulativeGasUsed).To(Equal(100))
+				})
+			})
+
+			Context("when an earlier transaction in the block is of another chaincode", func() {
+				BeforeEach(func() {
+					var err error
+					otherTransaction, err = GetSampleChaincodeTransaction("othercc", [][]byte{[]byte("1234567"), []byte("sample arg 3")}, []byte("sample-response 2"), []byte{}, "5678", "100")
+					Expect(err).ToNot(HaveOccurred())
+
+					sampleBlock = GetSampleBlockWithTransaction(31, []byte("12345abcd"), otherTransaction, sampleTransaction)
+					mockLedgerClient.QueryBlockByTxIDReturns(sampleBlock, nil)
+				})
+
+				It("does not count the message of its response as gas", func() {
+					var reply types.TxReceipt
+
+					err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(reply.GasUsed).To(Equal(250))
+					Expect(reply.CumulativeGasUsed).To(Equal(250))
+				})
+			})
+
+			Context("when the transaction is of another chaincode", func() {
+				BeforeEach(func() {
+					var err error
+					sampleTransaction, err = GetSampleChaincodeTransaction("othercc", [][]byte{[]byte(sampleAddress), []byte("sample arg 2")}, []byte("sample-response"), []byte{}, sampleTransactionID, "250")
+					Expect(err).ToNot(HaveOccurred())
+
+					sampleBlock = GetSampleBlockWithTransaction(31, []byte("12345abcd"), otherTransaction, sampleTransaction)
+					mockLedgerClient.QueryBlockByTxIDReturns(sampleBlock, nil)
+				})
+
+				It("reports that no gas was used by the transaction", func() {
+					var reply types.TxReceipt
+
+					err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(reply.GasUsed).To(Equal(0))
+					Expect(reply.CumulativeGasUsed).To(Equal(100))
+				})
+			})
+		})
+
 		Context("when the transaction has associated events", func() {
 			var (
 				msg, msg2    event.Event
@@ -531,6 +906,71 @@ var _ = Describe("Ethservice", func() {
 
 		})
 
+		Context("when the event payload is structured", func() {
+			BeforeEach(func() {
+				payload, err := json.Marshal(event.Payload{
+					Logs:       []event.Event{{Address: sampleAddress, Topics: []string{"sample-topic-1"}}},
+					Calls:      []event.CallEvent{{CallType: "call", Caller: sampleAddress, Callee: "0b", Depth: 1}},
+					Structured: true,
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				eventBytes, err := proto.Marshal(&peer.ChaincodeEvent{
+					ChaincodeId: "evmcc",
+					TxId:        sampleTransactionID,
+					EventName:   "Chaincode event",
+					Payload:     payload,
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				tx, err := GetSampleTransaction([][]byte{[]byte(sampleAddress), []byte("sample arg 2")}, []byte("sample-response"), eventBytes, sampleTransactionID)
+				Expect(err).ToNot(HaveOccurred())
+				*sampleTransaction = *tx
+
+				*sampleBlock = *GetSampleBlockWithTransaction(31, []byte("12345abcd"), sampleTransaction, otherTransaction)
+			})
+
+			It("returns the logs of the event", func() {
+				var reply types.TxReceipt
+
+				err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply.Logs).To(HaveLen(1))
+				Expect(reply.Logs[0].Address).To(Equal("0x" + sampleAddress))
+				Expect(reply.Logs[0].Topics).To(Equal([]string{"0xsample-topic-1"}))
+			})
+		})
+
+		Context("when the transaction emitted an audit event", func() {
+			BeforeEach(func() {
+				payload, err := json.Marshal(event.AuditEvent{Operation: "pause", Address: sampleAddress, Admin: "TestOrg"})
+				Expect(err).ToNot(HaveOccurred())
+
+				eventBytes, err := proto.Marshal(&peer.ChaincodeEvent{
+					ChaincodeId: "evmcc",
+					TxId:        sampleTransactionID,
+					EventName:   event.AuditEventPrefix + "pause",
+					Payload:     payload,
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				tx, err := GetSampleTransaction([][]byte{[]byte("pause"), []byte(sampleAddress)}, nil, eventBytes, sampleTransactionID)
+				Expect(err).ToNot(HaveOccurred())
+				*sampleTransaction = *tx
+
+				*sampleBlock = *GetSampleBlockWithTransaction(31, []byte("12345abcd"), sampleTransaction, otherTransaction)
+			})
+
+			It("returns a receipt without logs", func() {
+				var reply types.TxReceipt
+
+				err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply.Logs).To(BeEmpty())
+				Expect(reply.To).To(BeEmpty())
+			})
+		})
+
 		Context("when the transaction is creation of a smart contract", func() {
 			var contractAddress []byte
 			BeforeEach(func() {
@@ -616,7 +1056,7 @@ var _ = Describe("Ethservice", func() {
 				tooFewArgsTransaction, err = GetSampleTransaction([][]byte{[]byte("82373458")}, []byte("sample-response"), []byte{}, txnID1)
 				Expect(err).ToNot(HaveOccurred())
 
-				tooManyArgsTransaction, err = GetSampleTransaction([][]byte{[]byte("82373458"), []byte("sample-arg2"), []byte("sample-arg3")}, []byte("sample-response"), []byte{}, txnID2)
+				tooManyArgsTransaction, err = GetSampleTransaction([][]byte{[]byte("82373458"), []byte("sample-arg2"), []byte("sample-arg3"), []byte("sample-arg4"), []byte("sample-arg5")}, []byte("sample-response"), []byte{}, txnID2)
 				Expect(err).ToNot(HaveOccurred())
 
 				getCodeTransaction, err = GetSampleTransaction([][]byte{[]byte("getCode"), []byte("sample-arg")}, []byte("sample-response 2"), []byte{}, txnID3)
@@ -644,7 +1084,7 @@ var _ = Describe("Ethservice", func() {
 				}))
 			})
 
-			It("does not provide to field when the requested tx has more than 2 args", func() {
+			It("does not provide to field when the requested tx has more than 4 args", func() {
 				var reply types.TxReceipt
 				err := ethservice.GetTransactionReceipt(&http.Request{}, &txnID2, &reply)
 				Expect(err).ToNot(HaveOccurred())
@@ -660,104 +1100,225 @@ var _ = Describe("Ethservice", func() {
 				}))
 			})
 
-			It("does not provide to field when the requested tx is a getCode", func() {
-				var reply types.TxReceipt
-				err := ethservice.GetTransactionReceipt(&http.Request{}, &txnID3, &reply)
-				Expect(err).ToNot(HaveOccurred())
+			It("does not provide to field when the requested tx is a getCode", func() {
+				var reply types.TxReceipt
+				err := ethservice.GetTransactionReceipt(&http.Request{}, &txnID3, &reply)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(reply).To(Equal(types.TxReceipt{
+					TransactionHash:   "0x" + txnID3,
+					TransactionIndex:  "0x2",
+					BlockHash:         "0x" + hex.EncodeToString(blockHash(sampleBlock.GetHeader())),
+					BlockNumber:       "0x1f",
+					GasUsed:           0,
+					CumulativeGasUsed: 0,
+					Status:            "0x1",
+				}))
+			})
+		})
+
+		Context("when the ledger errors when processing a query for the block", func() {
+			BeforeEach(func() {
+				mockLedgerClient.QueryBlockByTxIDReturns(nil, errors.New("boom!"))
+			})
+
+			It("returns a corresponding error", func() {
+				var reply types.TxReceipt
+
+				err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+				Expect(reply).To(BeZero())
+			})
+		})
+	})
+
+	Describe("Accounts", func() {
+		var (
+			sampleAccount string
+			arg           string
+		)
+
+		BeforeEach(func() {
+			sampleAccount = "123456ABCD"
+			mockChClient.QueryReturns(channel.Response{
+				Payload: []byte(sampleAccount),
+			}, nil)
+
+		})
+
+		It("requests the user address from the evmscc based on the user cert", func() {
+			var reply []string
+
+			err := ethservice.Accounts(&http.Request{}, &arg, &reply)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+			chReq, reqOpts := mockChClient.QueryArgsForCall(0)
+			Expect(chReq).To(Equal(channel.Request{
+				ChaincodeID: evmcc,
+				Fcn:         "account",
+				Args:        [][]byte{},
+			}))
+
+			Expect(reqOpts).To(HaveLen(0))
+			expectedResponse := []string{"0x" + strings.ToLower(sampleAccount)}
+			Expect(reply).To(Equal(expectedResponse))
+		})
+
+		Context("when the ledger errors when processing a query", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
+			})
+
+			It("returns a corresponding error", func() {
+				var reply []string
+				err := ethservice.Accounts(&http.Request{}, &arg, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+				Expect(reply).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("EstimateGas", func() {
+		var sampleArgs *types.EthArgs
+
+		BeforeEach(func() {
+			sampleArgs = &types.EthArgs{
+				To:   "0x1234567123",
+				Data: "0xsample-data",
+			}
+
+			mockChClient.QueryReturns(channel.Response{
+				Responses: []*fab.TransactionProposalResponse{{
+					ProposalResponse: &peer.ProposalResponse{
+						Response: &peer.Response{Status: 200, Message: "300"},
+					},
+				}},
+			}, nil)
+		})
+
+		It("returns the gas used by the transaction when simulated by the evmcc", func() {
+			var reply string
+			err := ethservice.EstimateGas(&http.Request{}, sampleArgs, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal("0x12c"))
+
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+			chReq, reqOpts := mockChClient.QueryArgsForCall(0)
+			Expect(chReq).To(Equal(channel.Request{
+				ChaincodeID: evmcc,
+				Fcn:         "1234567123",
+				Args:        [][]byte{[]byte("sample-data")},
+			}))
+			Expect(reqOpts).To(HaveLen(0))
+		})
+
+		Context("when no address is provided", func() {
+			BeforeEach(func() {
+				sampleArgs.To = ""
+			})
+
+			It("estimates the gas of a contract deployment", func() {
+				var reply string
+				err := ethservice.EstimateGas(&http.Request{}, sampleArgs, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply).To(Equal("0x12c"))
+
+				Expect(mockChClient.QueryCallCount()).To(Equal(1))
+				chReq, _ := mockChClient.QueryArgsForCall(0)
+				Expect(chReq.Fcn).To(Equal(hex.EncodeToString(fab3.ZeroAddress)))
+			})
+		})
+
+		Context("when the evmcc does not report the gas used", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{}, nil)
+			})
+
+			It("returns zero", func() {
+				var reply string
+				err := ethservice.EstimateGas(&http.Request{}, sampleArgs, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply).To(Equal("0x0"))
+			})
+		})
+
+		Context("when the simulation fails", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
+			})
 
-				Expect(reply).To(Equal(types.TxReceipt{
-					TransactionHash:   "0x" + txnID3,
-					TransactionIndex:  "0x2",
-					BlockHash:         "0x" + hex.EncodeToString(blockHash(sampleBlock.GetHeader())),
-					BlockNumber:       "0x1f",
-					GasUsed:           0,
-					CumulativeGasUsed: 0,
-					Status:            "0x1",
-				}))
+			It("returns an error", func() {
+				var reply string
+				err := ethservice.EstimateGas(&http.Request{}, sampleArgs, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+				Expect(reply).To(BeEmpty())
 			})
 		})
 
-		Context("when the ledger errors when processing a query for the block", func() {
+		Context("when the contract reverts", func() {
 			BeforeEach(func() {
-				mockLedgerClient.QueryBlockByTxIDReturns(nil, errors.New("boom!"))
+				mockChClient.QueryReturns(channel.Response{}, status.New(status.EndorserServerStatus, 422,
+					"execution reverted: nope", []interface{}{"peer0", []byte{0xde, 0xad}}))
 			})
 
-			It("returns a corresponding error", func() {
-				var reply types.TxReceipt
-
-				err := ethservice.GetTransactionReceipt(&http.Request{}, &sampleTransactionID, &reply)
-				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
-				Expect(reply).To(BeZero())
+			It("returns a JSON-RPC error with the revert reason and data", func() {
+				var reply string
+				err := ethservice.EstimateGas(&http.Request{}, sampleArgs, &reply)
+				Expect(err).To(Equal(&json2.Error{
+					Code:    fab3.ExecutionRevertedCode,
+					Message: "execution reverted: nope",
+					Data:    "0xdead",
+				}))
+				Expect(reply).To(BeEmpty())
 			})
 		})
 	})
 
-	Describe("Accounts", func() {
-		var (
-			sampleAccount string
-			arg           string
-		)
-
+	Describe("GetBalance", func() {
 		BeforeEach(func() {
-			sampleAccount = "123456ABCD"
-			mockChClient.QueryReturns(channel.Response{
-				Payload: []byte(sampleAccount),
-			}, nil)
-
+			mockChClient.QueryReturns(channel.Response{Payload: []byte("1000")}, nil)
 		})
 
-		It("requests the user address from the evmscc based on the user cert", func() {
-			var reply []string
-
-			err := ethservice.Accounts(&http.Request{}, &arg, &reply)
+		It("returns the balance of the address", func() {
+			arg := []string{"0x1234567123", "latest"}
+			var reply string
+			err := ethservice.GetBalance(&http.Request{}, &arg, &reply)
 			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal("0x3e8"))
 
 			Expect(mockChClient.QueryCallCount()).To(Equal(1))
-			chReq, reqOpts := mockChClient.QueryArgsForCall(0)
+			chReq, _ := mockChClient.QueryArgsForCall(0)
 			Expect(chReq).To(Equal(channel.Request{
 				ChaincodeID: evmcc,
-				Fcn:         "account",
-				Args:        [][]byte{},
+				Fcn:         "getBalance",
+				Args:        [][]byte{[]byte("1234567123")},
 			}))
+		})
 
-			Expect(reqOpts).To(HaveLen(0))
-			expectedResponse := []string{"0x" + strings.ToLower(sampleAccount)}
-			Expect(reply).To(Equal(expectedResponse))
+		It("returns an error when no address is provided", func() {
+			var arg []string
+			var reply string
+			err := ethservice.GetBalance(&http.Request{}, &arg, &reply)
+			Expect(err).To(HaveOccurred())
+			Expect(mockChClient.QueryCallCount()).To(Equal(0))
 		})
 
-		Context("when the ledger errors when processing a query", func() {
+		Context("when the ledger errors when processing the query", func() {
 			BeforeEach(func() {
 				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
 			})
 
 			It("returns a corresponding error", func() {
-				var reply []string
-				err := ethservice.Accounts(&http.Request{}, &arg, &reply)
+				arg := []string{"0x1234567123"}
+				var reply string
+				err := ethservice.GetBalance(&http.Request{}, &arg, &reply)
 				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
 				Expect(reply).To(BeEmpty())
 			})
 		})
 	})
 
-	Describe("EstimateGas", func() {
-		It("always returns zero", func() {
-			var reply string
-			err := ethservice.EstimateGas(&http.Request{}, &types.EthArgs{}, &reply)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(reply).To(Equal("0x0"))
-		})
-	})
-
-	Describe("GetBalance", func() {
-		It("always returns zero", func() {
-			arg := make([]string, 2)
-			var reply string
-			err := ethservice.GetBalance(&http.Request{}, &arg, &reply)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(reply).To(Equal("0x0"))
-		})
-	})
-
 	Describe("GetBlockByNumber", func() {
 		Context("when provided with bad parameters", func() {
 			var reply types.Block
@@ -872,6 +1433,39 @@ var _ = Describe("Ethservice", func() {
 						Expect(txns[1]).To(BeEquivalentTo("0x1234"))
 					})
 
+					It("returns the gas used by the valid transactions in the block", func() {
+						tx1, err := GetSampleTransactionWithGasUsed([][]byte{[]byte("12345678"), []byte("sample arg 1")}, []byte("sample-response1"), []byte{}, "5678", "100")
+						Expect(err).ToNot(HaveOccurred())
+						tx2, err := GetSampleTransactionWithGasUsed([][]byte{[]byte("98765432"), []byte("sample arg 2")}, []byte("sample-response2"), []byte{}, "1234", "250")
+						Expect(err).ToNot(HaveOccurred())
+						tx3, err := GetSampleTransactionWithGasUsed([][]byte{[]byte("98765432"), []byte("sample arg 3")}, []byte("sample-response3"), []byte{}, "4321", "1000")
+						Expect(err).ToNot(HaveOccurred())
+
+						sampleBlock := GetSampleBlockWithTransaction(uintBlockNumber, []byte("12345abcd"), tx1, tx2, tx3)
+						sampleBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER][2] = byte(peer.TxValidationCode_MVCC_READ_CONFLICT)
+						mockLedgerClient.QueryBlockReturns(sampleBlock, nil)
+
+						err = ethservice.GetBlockByNumber(&http.Request{}, &args, &reply)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(reply.GasUsed).To(Equal("0x15e"))
+					})
+
+					It("does not count the transactions of other chaincodes in the gas used by the block", func() {
+						tx1, err := GetSampleTransactionWithGasUsed([][]byte{[]byte("12345678"), []byte("sample arg 1")}, []byte("sample-response1"), []byte{}, "5678", "100")
+						Expect(err).ToNot(HaveOccurred())
+						tx2, err := GetSampleChaincodeTransaction("othercc", [][]byte{[]byte("transfer"), []byte("4000")}, []byte("sample-response2"), []byte{}, "1234", "4000")
+						Expect(err).ToNot(HaveOccurred())
+						tx3, err := GetSampleTransactionWithGasUsed([][]byte{[]byte("98765432"), []byte("sample arg 3")}, []byte("sample-response3"), []byte{}, "4321", "250")
+						Expect(err).ToNot(HaveOccurred())
+
+						sampleBlock := GetSampleBlockWithTransaction(uintBlockNumber, []byte("12345abcd"), tx1, tx2, tx3)
+						mockLedgerClient.QueryBlockReturns(sampleBlock, nil)
+
+						err = ethservice.GetBlockByNumber(&http.Request{}, &args, &reply)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(reply.GasUsed).To(Equal("0x15e"))
+					})
+
 					It("requests a block by number, but there is an invalid transaction, the invalid transaction does not show up", func() {
 						sampleBlock := GetSampleBlock(uintBlockNumber)
 						// invalidate a transaction for some reason
@@ -1070,6 +1664,50 @@ var _ = Describe("Ethservice", func() {
 			Expect(reply.Input).To(Equal("0xsample arg 2"))
 		})
 
+		Context("when the requested transaction provided a gas limit", func() {
+			It("gets the transaction", func() {
+				txID := "1234567123"
+				tx, err := GetSampleTransaction([][]byte{[]byte("82373458"), []byte("sample-input"), []byte("50000")}, []byte("sample-response"), []byte{}, txID)
+				Expect(err).ToNot(HaveOccurred())
+				block := GetSampleBlockWithTransaction(31, []byte("12345abcd"), tx)
+				mockLedgerClient.QueryBlockByTxIDReturns(block, nil)
+
+				err = ethservice.GetTransactionByHash(&http.Request{}, &txID, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply.To).To(Equal("0x82373458"))
+				Expect(reply.Input).To(Equal("0xsample-input"))
+				Expect(reply.From).To(Equal(addrFromCert))
+			})
+
+			It("gets the transaction when it also transferred value", func() {
+				txID := "1234567123"
+				tx, err := GetSampleTransaction([][]byte{[]byte("82373458"), []byte("sample-input"), []byte(""), []byte("100")}, []byte("sample-response"), []byte{}, txID)
+				Expect(err).ToNot(HaveOccurred())
+				block := GetSampleBlockWithTransaction(31, []byte("12345abcd"), tx)
+				mockLedgerClient.QueryBlockByTxIDReturns(block, nil)
+
+				err = ethservice.GetTransactionByHash(&http.Request{}, &txID, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply.To).To(Equal("0x82373458"))
+				Expect(reply.Input).To(Equal("0xsample-input"))
+			})
+		})
+
+		Context("when the requested transaction minted balance", func() {
+			It("does not provide to and input fields", func() {
+				txID := "1234567123"
+				tx, err := GetSampleTransaction([][]byte{[]byte("mint"), []byte("82373458"), []byte("100")}, []byte("100"), []byte{}, txID)
+				Expect(err).ToNot(HaveOccurred())
+				block := GetSampleBlockWithTransaction(31, []byte("12345abcd"), tx)
+				mockLedgerClient.QueryBlockByTxIDReturns(block, nil)
+
+				err = ethservice.GetTransactionByHash(&http.Request{}, &txID, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply.To).To(BeEmpty())
+				Expect(reply.Input).To(BeEmpty())
+			})
+		})
+
 		Context("when requested transaction is not an evm smart contract transaction", func() {
 			var (
 				tooFewArgsTransaction, tooManyArgsTransaction, getCodeTransaction *peer.ProcessedTransaction
@@ -1086,7 +1724,7 @@ var _ = Describe("Ethservice", func() {
 				tooFewArgsTransaction, err = GetSampleTransaction([][]byte{[]byte("82373458")}, []byte("sample-response"), []byte{}, txnID1)
 				Expect(err).ToNot(HaveOccurred())
 
-				tooManyArgsTransaction, err = GetSampleTransaction([][]byte{[]byte("82373458"), []byte("sample-arg2"), []byte("sample-arg3")}, []byte("sample-response"), []byte{}, txnID2)
+				tooManyArgsTransaction, err = GetSampleTransaction([][]byte{[]byte("82373458"), []byte("sample-arg2"), []byte("sample-arg3"), []byte("sample-arg4"), []byte("sample-arg5")}, []byte("sample-response"), []byte{}, txnID2)
 				Expect(err).ToNot(HaveOccurred())
 
 				getCodeTransaction, err = GetSampleTransaction([][]byte{[]byte("getCode"), []byte("sample-arg")}, []byte("sample-response 2"), []byte{}, txnID3)
@@ -1111,7 +1749,7 @@ var _ = Describe("Ethservice", func() {
 				}))
 			})
 
-			It("does not provide to field when the requested transaction has more than 2 args", func() {
+			It("does not provide to field when the requested transaction has more than 4 args", func() {
 				var reply types.Transaction
 				err := ethservice.GetTransactionByHash(&http.Request{}, &txnID2, &reply)
 				Expect(err).ToNot(HaveOccurred())
@@ -1366,11 +2004,280 @@ var _ = Describe("Ethservice", func() {
 	})
 
 	Describe("GetTransactionCount", func() {
-		It("always returns 0x0", func() {
+		BeforeEach(func() {
+			mockChClient.QueryReturns(channel.Response{Payload: []byte("26")}, nil)
+		})
+
+		It("returns the nonce of the address", func() {
+			arg := []string{"0x1234567123", "latest"}
+			var reply string
+			err := ethservice.GetTransactionCount(&http.Request{}, &arg, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal("0x1a"))
+
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+			chReq, _ := mockChClient.QueryArgsForCall(0)
+			Expect(chReq).To(Equal(channel.Request{
+				ChaincodeID: evmcc,
+				Fcn:         "getNonce",
+				Args:        [][]byte{[]byte("1234567123")},
+			}))
+		})
+
+		It("returns an error when no address is provided", func() {
+			var arg []string
+			var reply string
+			err := ethservice.GetTransactionCount(&http.Request{}, &arg, &reply)
+			Expect(err).To(HaveOccurred())
+			Expect(mockChClient.QueryCallCount()).To(Equal(0))
+		})
+
+		Context("when the ledger errors when processing the query", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
+			})
+
+			It("returns a corresponding error", func() {
+				arg := []string{"0x1234567123"}
+				var reply string
+				err := ethservice.GetTransactionCount(&http.Request{}, &arg, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+				Expect(reply).To(BeEmpty())
+			})
+		})
+
+		Context("when the nonce cannot be parsed", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{Payload: []byte("many")}, nil)
+			})
+
+			It("returns a corresponding error", func() {
+				arg := []string{"0x1234567123"}
+				var reply string
+				err := ethservice.GetTransactionCount(&http.Request{}, &arg, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to parse nonce")))
+			})
+		})
+	})
+
+	Describe("GetStorageAt", func() {
+		BeforeEach(func() {
+			mockChClient.QueryReturns(channel.Response{Payload: []byte("000000000000000000000000000000000000000000000000000000000000002a")}, nil)
+		})
+
+		It("returns the word stored at the position", func() {
+			arg := []string{"0x1234567123", "0x0", "latest"}
+			var reply string
+			err := ethservice.GetStorageAt(&http.Request{}, &arg, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal("0x000000000000000000000000000000000000000000000000000000000000002a"))
+
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+			chReq, _ := mockChClient.QueryArgsForCall(0)
+			Expect(chReq).To(Equal(channel.Request{
+				ChaincodeID: evmcc,
+				Fcn:         "getStorageAt",
+				Args:        [][]byte{[]byte("1234567123"), []byte("0")},
+			}))
+		})
+
+		It("returns an error when no position is provided", func() {
+			arg := []string{"0x1234567123"}
 			var reply string
-			err := ethservice.GetTransactionCount(&http.Request{}, nil, &reply)
+			err := ethservice.GetStorageAt(&http.Request{}, &arg, &reply)
+			Expect(err).To(MatchError("need at least 2 params, got 1"))
+			Expect(mockChClient.QueryCallCount()).To(Equal(0))
+		})
+
+		Context("when the ledger errors when processing the query", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
+			})
+
+			It("returns a corresponding error", func() {
+				arg := []string{"0x1234567123", "0x0"}
+				var reply string
+				err := ethservice.GetStorageAt(&http.Request{}, &arg, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+				Expect(reply).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("GetAccount", func() {
+		BeforeEach(func() {
+			mockChClient.QueryReturns(channel.Response{Payload: []byte(`{"address":"1234567123123456712312345671231234567123","exists":true,"balance":300,"sequence":2,"codeSize":32,"codeHash":"abcdef","permissions":["send","call"]}`)}, nil)
+		})
+
+		It("returns the account with hex encoded quantities", func() {
+			arg := []string{"0x1234567123123456712312345671231234567123", "latest"}
+			var reply types.Account
+			err := ethservice.GetAccount(&http.Request{}, &arg, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal(types.Account{
+				Address:     "0x1234567123123456712312345671231234567123",
+				Exists:      true,
+				Balance:     "0x12c",
+				Sequence:    "0x2",
+				CodeSize:    "0x20",
+				CodeHash:    "0xabcdef",
+				Permissions: []string{"send", "call"},
+			}))
+
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+			chReq, _ := mockChClient.QueryArgsForCall(0)
+			Expect(chReq).To(Equal(channel.Request{
+				ChaincodeID: evmcc,
+				Fcn:         "getAccount",
+				Args:        [][]byte{[]byte("1234567123123456712312345671231234567123")},
+			}))
+		})
+
+		Context("when the account does not exist", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{Payload: []byte(`{"address":"1234567123123456712312345671231234567123","exists":false,"balance":0,"sequence":0,"codeSize":0,"permissions":[]}`)}, nil)
+			})
+
+			It("returns the account without a code hash", func() {
+				arg := []string{"0x1234567123123456712312345671231234567123"}
+				var reply types.Account
+				err := ethservice.GetAccount(&http.Request{}, &arg, &reply)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reply).To(Equal(types.Account{
+					Address:     "0x1234567123123456712312345671231234567123",
+					Balance:     "0x0",
+					Sequence:    "0x0",
+					CodeSize:    "0x0",
+					Permissions: []string{},
+				}))
+			})
+		})
+
+		It("returns an error when no address is provided", func() {
+			var arg []string
+			var reply types.Account
+			err := ethservice.GetAccount(&http.Request{}, &arg, &reply)
+			Expect(err).To(HaveOccurred())
+			Expect(mockChClient.QueryCallCount()).To(Equal(0))
+		})
+
+		Context("when the ledger errors when processing the query", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
+			})
+
+			It("returns a corresponding error", func() {
+				arg := []string{"0x1234567123"}
+				var reply types.Account
+				err := ethservice.GetAccount(&http.Request{}, &arg, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+			})
+		})
+
+		Context("when the account cannot be unmarshaled", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{Payload: []byte("not json")}, nil)
+			})
+
+			It("returns a corresponding error", func() {
+				arg := []string{"0x1234567123"}
+				var reply types.Account
+				err := ethservice.GetAccount(&http.Request{}, &arg, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to unmarshal account")))
+			})
+		})
+	})
+
+	Describe("Multicall", func() {
+		var calls []types.EthArgs
+
+		BeforeEach(func() {
+			calls = []types.EthArgs{
+				{To: "0x1234567123", Data: "0x6d4ce63c"},
+				{To: "0x1234567123", Data: "0xdeadbeef", Gas: "0x186a0", Value: "0xa"},
+			}
+			mockChClient.QueryReturns(channel.Response{Payload: []byte(`[{"output":"2a"},{"output":"dead","error":"execution reverted","reverted":true}]`)}, nil)
+		})
+
+		It("runs the calls in a single multicall query", func() {
+			var reply []types.CallResult
+			err := ethservice.Multicall(&http.Request{}, &calls, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal([]types.CallResult{
+				{Success: true, ReturnData: "0x2a"},
+				{Success: false, ReturnData: "0xdead", Error: "execution reverted", Reverted: true},
+			}))
+
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+			chReq, _ := mockChClient.QueryArgsForCall(0)
+			Expect(chReq.ChaincodeID).To(Equal(evmcc))
+			Expect(chReq.Fcn).To(Equal("multicall"))
+			Expect(chReq.Args).To(HaveLen(1))
+			Expect(chReq.Args[0]).To(MatchJSON(`[{"to":"1234567123","input":"6d4ce63c"},{"to":"1234567123","input":"deadbeef","gas":"100000","value":"10"}]`))
+		})
+
+		It("returns an error for a call with transient data without failing the others", func() {
+			calls = append([]types.EthArgs{{To: "0x1234567123", Data: "0x6d4ce63c", Transient: map[string]string{"input": "0x6d4ce63c"}}}, calls...)
+			var reply []types.CallResult
+			err := ethservice.Multicall(&http.Request{}, &calls, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(Equal([]types.CallResult{
+				{Error: "Transient data is not supported by multicall"},
+				{Success: true, ReturnData: "0x2a"},
+				{Success: false, ReturnData: "0xdead", Error: "execution reverted", Reverted: true},
+			}))
+
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+			chReq, _ := mockChClient.QueryArgsForCall(0)
+			Expect(chReq.Args[0]).To(MatchJSON(`[{"to":"1234567123","input":"6d4ce63c"},{"to":"1234567123","input":"deadbeef","gas":"100000","value":"10"}]`))
+		})
+
+		It("returns an error for a call with malformed gas without failing the others", func() {
+			calls = append(calls[:1], types.EthArgs{To: "0x1234567123", Gas: "lots"}, calls[1])
+			var reply []types.CallResult
+			err := ethservice.Multicall(&http.Request{}, &calls, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(HaveLen(3))
+			Expect(reply[0]).To(Equal(types.CallResult{Success: true, ReturnData: "0x2a"}))
+			Expect(reply[1].Success).To(BeFalse())
+			Expect(reply[1].Error).To(HavePrefix("Failed to parse gas"))
+			Expect(reply[2]).To(Equal(types.CallResult{Success: false, ReturnData: "0xdead", Error: "execution reverted", Reverted: true}))
+			Expect(mockChClient.QueryCallCount()).To(Equal(1))
+		})
+
+		It("does not query the chaincode when no call is well formed", func() {
+			calls = []types.EthArgs{{To: "0x1234567123", Value: "lots"}}
+			var reply []types.CallResult
+			err := ethservice.Multicall(&http.Request{}, &calls, &reply)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(reply).To(Equal("0x0"))
+			Expect(reply).To(HaveLen(1))
+			Expect(reply[0].Error).To(HavePrefix("Failed to parse value"))
+			Expect(mockChClient.QueryCallCount()).To(Equal(0))
+		})
+
+		Context("when the ledger errors when processing the query", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{}, errors.New("boom!"))
+			})
+
+			It("returns a corresponding error", func() {
+				var reply []types.CallResult
+				err := ethservice.Multicall(&http.Request{}, &calls, &reply)
+				Expect(err).To(MatchError(ContainSubstring("Failed to query the ledger")))
+				Expect(reply).To(BeEmpty())
+			})
+		})
+
+		Context("when the chaincode does not return a result for every call", func() {
+			BeforeEach(func() {
+				mockChClient.QueryReturns(channel.Response{Payload: []byte(`[{"output":"2a"}]`)}, nil)
+			})
+
+			It("returns an error", func() {
+				var reply []types.CallResult
+				err := ethservice.Multicall(&http.Request{}, &calls, &reply)
+				Expect(err).To(MatchError("Received 1 results for 2 calls"))
+			})
 		})
 	})
 })
@@ -1475,11 +2382,23 @@ func GetSampleBlockWithTransaction(blockNumber uint64, blkHash []byte, txns ...*
 }
 
 func GetSampleTransaction(inputArgs [][]byte, txResponse, eventBytes []byte, txId string) (*peer.ProcessedTransaction, error) {
+	return GetSampleTransactionWithGasUsed(inputArgs, txResponse, eventBytes, txId, "")
+}
+
+func GetSampleTransactionWithGasUsed(inputArgs [][]byte, txResponse, eventBytes []byte, txId string, gasUsed string) (*peer.ProcessedTransaction, error) {
+	return GetSampleChaincodeTransaction(evmcc, inputArgs, txResponse, eventBytes, txId, gasUsed)
+}
+
+func GetSampleChaincodeTransaction(ccName string, inputArgs [][]byte, txResponse, eventBytes []byte, txId string, message string) (*peer.ProcessedTransaction, error) {
 
 	respPayload := &peer.ChaincodeAction{
+		ChaincodeId: &peer.ChaincodeID{
+			Name: ccName,
+		},
 		Events: eventBytes,
 		Response: &peer.Response{
 			Payload: txResponse,
+			Message: message,
 		},
 	}
 
@@ -1500,7 +2419,7 @@ func GetSampleTransaction(inputArgs [][]byte, txResponse, eventBytes []byte, txI
 	invokeSpec := &peer.ChaincodeInvocationSpec{
 		ChaincodeSpec: &peer.ChaincodeSpec{
 			ChaincodeId: &peer.ChaincodeID{
-				Name: evmcc,
+				Name: ccName,
 			},
 			Input: &peer.ChaincodeInput{
 				Args: inputArgs,
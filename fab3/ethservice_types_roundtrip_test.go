@@ -59,7 +59,7 @@ var _ = Describe("ethereum json rpc struct fields", func() {
 		})
 
 		It("for Block with the proper cases", func() {
-			fieldNames := []string{"number", "hash", "parentHash", "transactions", "gasLimit"}
+			fieldNames := []string{"number", "hash", "parentHash", "transactions", "gasLimit", "gasUsed"}
 			assertTypeMarshalsJSONFields(fieldNames, types.Block{})
 		})
 	})
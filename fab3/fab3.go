@@ -21,7 +21,7 @@ type Fab3 struct {
 	HTTPServer *http.Server
 }
 
-func NewFab3(service EthService, port int) *Fab3 {
+func NewFab3(service EthService, debugService DebugService, traceService TraceService, port int) *Fab3 {
 	rpcServer := rpc.NewServer()
 
 	proxy := &Fab3{
@@ -36,9 +36,15 @@ func NewFab3(service EthService, port int) *Fab3 {
 	if err := rpcServer.RegisterService(&NetService{}, "net"); err != nil {
 		panic(msg)
 	}
+	if err := rpcServer.RegisterService(debugService, "debug"); err != nil {
+		panic(msg)
+	}
+	if err := rpcServer.RegisterService(traceService, "trace"); err != nil {
+		panic(msg)
+	}
 
 	r := mux.NewRouter()
-	r.Handle("/", proxy.RPCServer)
+	r.Handle("/", &batchHandler{rpcServer: proxy.RPCServer, service: service})
 
 	allowedHeaders := handlers.AllowedHeaders([]string{"Origin", "Content-Type"})
 	allowedOrigins := handlers.AllowedOrigins([]string{"*"})
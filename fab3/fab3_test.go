@@ -9,13 +9,16 @@ package fab3_test
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"strings"
 
+	"github.com/gorilla/rpc/v2/json2"
 	"github.com/hyperledger/fabric-chaincode-evm/fab3"
+	"github.com/hyperledger/fabric-chaincode-evm/fab3/types"
 	fab3_mocks "github.com/hyperledger/fabric-chaincode-evm/mocks/fab3"
 
 	. "github.com/onsi/ginkgo"
@@ -26,23 +29,27 @@ import (
 var _ = Describe("Fab3", func() {
 
 	var (
-		proxy          *fab3.Fab3
-		proxyAddr      string
-		mockEthService *fab3_mocks.MockEthService
-		req            *http.Request
-		proxyDoneChan  chan struct{}
-		client         *http.Client
-		port           int
+		proxy            *fab3.Fab3
+		proxyAddr        string
+		mockEthService   *fab3_mocks.MockEthService
+		mockDebugService *fab3_mocks.MockDebugService
+		mockTraceService *fab3_mocks.MockTraceService
+		req              *http.Request
+		proxyDoneChan    chan struct{}
+		client           *http.Client
+		port             int
 	)
 
 	BeforeEach(func() {
 		port = config.GinkgoConfig.ParallelNode + 5000
 		mockEthService = &fab3_mocks.MockEthService{}
+		mockDebugService = &fab3_mocks.MockDebugService{}
+		mockTraceService = &fab3_mocks.MockTraceService{}
 		client = &http.Client{}
 
 		proxyDoneChan = make(chan struct{}, 1)
 		var err error
-		proxy = fab3.NewFab3(mockEthService, port)
+		proxy = fab3.NewFab3(mockEthService, mockDebugService, mockTraceService, port)
 		Expect(err).ToNot(HaveOccurred())
 	})
 
@@ -110,6 +117,111 @@ var _ = Describe("Fab3", func() {
 			Expect(respBody).To(Equal(expectedBody))
 		})
 
+		It("returns revert errors with their JSON-RPC code and data", func() {
+			mockEthService.CallStub = func(r *http.Request, args *types.EthArgs, reply *string) error {
+				return &json2.Error{Code: fab3.ExecutionRevertedCode, Message: "execution reverted: nope", Data: "0xdead"}
+			}
+
+			var err error
+			body := strings.NewReader(`{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x1234","data":"0x5678"}],"id":1}`)
+			req, err = http.NewRequest("POST", proxyAddr, body)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+
+			rBody, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rBody).To(MatchJSON(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: nope","data":"0xdead"}}`))
+		})
+
+		Context("when a batch of requests is sent", func() {
+			post := func(body string) []byte {
+				req, err := http.NewRequest("POST", proxyAddr, strings.NewReader(body))
+				Expect(err).ToNot(HaveOccurred())
+				req.Header.Set("Content-Type", "application/json")
+
+				resp, err := client.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+
+				rBody, err := ioutil.ReadAll(resp.Body)
+				Expect(err).ToNot(HaveOccurred())
+				return rBody
+			}
+
+			BeforeEach(func() {
+				mockEthService.MulticallStub = func(r *http.Request, args *[]types.EthArgs, reply *[]types.CallResult) error {
+					*reply = []types.CallResult{
+						{Success: true, ReturnData: "0x2a"},
+						{ReturnData: "0xdead", Error: "execution reverted: nope", Reverted: true},
+					}
+					return nil
+				}
+			})
+
+			It("serves the eth_call requests with a single multicall and the others one by one", func() {
+				rBody := post(`[
+					{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x1234","data":"0x5678"},"latest"],"id":1},
+					{"jsonrpc":"2.0","method":"eth_getCode","params":["0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b"],"id":2},
+					{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x1234","data":"0x9abc"}],"id":3}
+				]`)
+				Expect(rBody).To(MatchJSON(`[
+					{"jsonrpc":"2.0","id":1,"result":"0x2a"},
+					{"jsonrpc":"2.0","id":2,"result":"0x11110"},
+					{"jsonrpc":"2.0","id":3,"error":{"code":3,"message":"execution reverted: nope","data":"0xdead"}}
+				]`))
+
+				Expect(mockEthService.MulticallCallCount()).To(Equal(1))
+				_, args, _ := mockEthService.MulticallArgsForCall(0)
+				Expect(*args).To(Equal([]types.EthArgs{{To: "0x1234", Data: "0x5678"}, {To: "0x1234", Data: "0x9abc"}}))
+				Expect(mockEthService.CallCallCount()).To(Equal(0))
+				Expect(mockEthService.GetCodeCallCount()).To(Equal(1))
+			})
+
+			It("returns the error of a failed call for its eth_call request only", func() {
+				mockEthService.MulticallStub = func(r *http.Request, args *[]types.EthArgs, reply *[]types.CallResult) error {
+					*reply = []types.CallResult{
+						{Error: "Failed to parse gas: invalid syntax"},
+						{Success: true, ReturnData: "0x2a"},
+					}
+					return nil
+				}
+
+				rBody := post(`[
+					{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x1234","data":"0x5678","gas":"lots"}],"id":1},
+					{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x1234","data":"0x9abc"}],"id":2}
+				]`)
+				Expect(rBody).To(MatchJSON(`[
+					{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"Failed to parse gas: invalid syntax","data":null}},
+					{"jsonrpc":"2.0","id":2,"result":"0x2a"}
+				]`))
+			})
+
+			It("returns the error of a failed call which mentions a revert as a server error", func() {
+				mockEthService.MulticallStub = func(r *http.Request, args *[]types.EthArgs, reply *[]types.CallResult) error {
+					*reply = []types.CallResult{{ReturnData: "0x", Error: "failed to invoke chaincode: execution reverted"}}
+					return nil
+				}
+
+				rBody := post(`[{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x1234","data":"0x5678"}],"id":1}]`)
+				Expect(rBody).To(MatchJSON(`[{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"failed to invoke chaincode: execution reverted","data":null}}]`))
+			})
+
+			It("returns the error of the multicall for every eth_call request", func() {
+				mockEthService.MulticallReturns(errors.New("boom!"))
+
+				rBody := post(`[{"jsonrpc":"2.0","method":"eth_call","params":[{"to":"0x1234","data":"0x5678"}],"id":1}]`)
+				Expect(rBody).To(MatchJSON(`[{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"boom!","data":null}}]`))
+			})
+
+			It("returns an error for an empty batch", func() {
+				rBody := post(`[]`)
+				Expect(rBody).To(MatchJSON(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"empty batch","data":null}}`))
+				Expect(mockEthService.MulticallCallCount()).To(Equal(0))
+			})
+		})
+
 		It("starts a server that uses the hardcoded netservice", func() {
 			var err error
 			body := strings.NewReader(`{"jsonrpc":"2.0","method":"net_version","id":1}`)
@@ -136,6 +248,57 @@ var _ = Describe("Fab3", func() {
 			Expect(respBody).To(Equal(expectedBody))
 		})
 
+		It("starts a server that uses the provided debugservice", func() {
+			mockDebugService.TraceCallStub = func(r *http.Request, args *types.EthArgs, reply *types.TraceResult) error {
+				*reply = types.TraceResult{Gas: 1, ReturnValue: "2a", StructLogs: []types.StructLog{{Op: "STOP", Depth: 1, Stack: []string{}, Memory: []string{}}}}
+				return nil
+			}
+
+			var err error
+			body := strings.NewReader(`{"jsonrpc":"2.0","method":"debug_traceCall","params":[{"to":"0x1234","data":"0x5678"},"latest"],"id":1}`)
+			req, err = http.NewRequest("POST", proxyAddr, body)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+
+			rBody, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rBody).To(MatchJSON(`{"jsonrpc":"2.0","id":1,"result":{"gas":1,"failed":false,"returnValue":"2a","structLogs":[
+				{"pc":0,"op":"STOP","gas":0,"gasCost":0,"depth":1,"stack":[],"memory":[]}
+			]}}`))
+
+			Expect(mockDebugService.TraceCallCallCount()).To(Equal(1))
+			_, args, _ := mockDebugService.TraceCallArgsForCall(0)
+			Expect(*args).To(Equal(types.EthArgs{To: "0x1234", Data: "0x5678"}))
+		})
+
+		It("starts a server that uses the provided traceservice", func() {
+			mockTraceService.TransactionStub = func(r *http.Request, txID *string, reply *[]types.Trace) error {
+				*reply = []types.Trace{{Action: types.TraceAction{From: "0x12", Value: "0x0"}, Type: "create", TraceAddress: []int{}}}
+				return nil
+			}
+
+			var err error
+			body := strings.NewReader(`{"jsonrpc":"2.0","method":"trace_transaction","params":["0x1234"],"id":1}`)
+			req, err = http.NewRequest("POST", proxyAddr, body)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+
+			rBody, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rBody).To(MatchJSON(`{"jsonrpc":"2.0","id":1,"result":[{"action":{"from":"0x12","value":"0x0"},"subtraces":0,"traceAddress":[],
+				"type":"create","blockHash":"","blockNumber":"","transactionHash":"","transactionPosition":""}]}`))
+
+			Expect(mockTraceService.TransactionCallCount()).To(Equal(1))
+			_, txID, _ := mockTraceService.TransactionArgsForCall(0)
+			Expect(*txID).To(Equal("0x1234"))
+		})
+
 		Context("when the request has Cross-Origin Resource Sharing Headers", func() {
 			BeforeEach(func() {
 				var err error
@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fab3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/hyperledger/fabric-chaincode-evm/event"
+	"github.com/hyperledger/fabric-chaincode-evm/fab3/types"
+)
+
+//go:generate counterfeiter -o ../mocks/fab3/mocktraceservice.go --fake-name MockTraceService ./ TraceService
+
+// TraceService is the trace namespace of the OpenEthereum json-rpc, which
+// returns the internal transactions the EVM chaincode recorded in the events
+// of transactions.
+type TraceService interface {
+	Transaction(r *http.Request, txID *string, reply *[]types.Trace) error
+}
+
+type traceService struct {
+	ledgerClient LedgerClient
+	logger       *zap.SugaredLogger
+}
+
+func NewTraceService(ledgerClient LedgerClient, logger *zap.SugaredLogger) TraceService {
+	return &traceService{
+		ledgerClient: ledgerClient,
+		logger:       logger.Named("traceservice"),
+	}
+}
+
+// Transaction returns the call or deployment of a transaction followed by the
+// calls its contracts made, in the order they were made. Gas is not recorded,
+// so the traces have no gas and gasUsed fields.
+//
+// https://openethereum.github.io/JSONRPC-trace-module#trace_transaction
+func (s *traceService) Transaction(r *http.Request, txID *string, reply *[]types.Trace) error {
+	strippedTxID := strip0x(*txID)
+	if strippedTxID == "" {
+		return fmt.Errorf("txID was empty")
+	}
+	s.logger.Debug("Transaction", strippedTxID)
+
+	block, err := s.ledgerClient.QueryBlockByTxID(fab.TransactionID(strippedTxID))
+	if err != nil {
+		return fmt.Errorf("Failed to query the ledger: %s", err)
+	}
+
+	index, txPayload, err := findTransaction(strippedTxID, block.GetData().GetData())
+	if err != nil {
+		return fmt.Errorf("Failed to parse through transactions in the block: %s", err)
+	}
+	if txPayload == nil {
+		return fmt.Errorf("Transaction %s was not found in block %d", strippedTxID, block.GetHeader().GetNumber())
+	}
+
+	args, respPayload, err := getTransactionArgs(txPayload)
+	if err != nil {
+		return err
+	}
+	if !isEVMCall(args) {
+		return fmt.Errorf("Transaction %s is not a call or deployment of a contract", strippedTxID)
+	}
+
+	_, _, from, _, err := getTransactionInformation(txPayload)
+	if err != nil {
+		return err
+	}
+
+	calls, err := fabricEventToCalls(respPayload.GetEvents())
+	if err != nil {
+		return err
+	}
+
+	traces := make([]types.Trace, len(calls)+1)
+	traces[0] = transactionTrace(args, from, respPayload.GetResponse().GetPayload())
+	traces[0].TraceAddress = []int{}
+	// traceAddresses holds the trace address of the latest call at each depth
+	traceAddresses := [][]int{{}}
+	// callers holds the index of the trace of the latest call at each depth
+	callers := []int{0}
+	for i, call := range calls {
+		depth := int(call.Depth)
+		if depth < 1 || depth > len(callers) {
+			return fmt.Errorf("Call %d of transaction %s has an invalid depth %d", i, strippedTxID, call.Depth)
+		}
+
+		caller := &traces[callers[depth-1]]
+		traceAddress := append(append([]int{}, traceAddresses[depth-1]...), caller.Subtraces)
+		caller.Subtraces++
+
+		traces[i+1] = callTrace(call)
+		traces[i+1].TraceAddress = traceAddress
+		traceAddresses = append(traceAddresses[:depth], traceAddress)
+		callers = append(callers[:depth], i+1)
+	}
+
+	blkHeader := block.GetHeader()
+	for i := range traces {
+		traces[i].BlockHash = "0x" + hex.EncodeToString(blockHash(blkHeader))
+		traces[i].BlockNumber = "0x" + strconv.FormatUint(blkHeader.GetNumber(), 16)
+		traces[i].TransactionHash = "0x" + strippedTxID
+		traces[i].TransactionPosition = index
+	}
+
+	*reply = traces
+	return nil
+}
+
+// transactionTrace returns the trace of the call or deployment of a
+// transaction, from the arguments it passed to the EVM chaincode and the
+// payload of its response.
+func transactionTrace(args [][]byte, from string, response []byte) types.Trace {
+	value := "0x0"
+	if len(args) > 3 && len(args[3]) != 0 {
+		if v, err := strconv.ParseUint(string(args[3]), 10, 64); err == nil {
+			value = "0x" + strconv.FormatUint(v, 16)
+		}
+	}
+
+	callee, err := hex.DecodeString(string(args[0]))
+	if err == nil && bytes.Equal(callee, ZeroAddress) {
+		return types.Trace{
+			Action: types.TraceAction{From: from, Init: "0x" + string(args[1]), Value: value},
+			Result: &types.TraceOutput{Address: "0x" + string(response)},
+			Type:   "create",
+		}
+	}
+
+	return types.Trace{
+		Action: types.TraceAction{
+			CallType: "call",
+			From:     from,
+			To:       "0x" + string(args[0]),
+			Input:    "0x" + string(args[1]),
+			Value:    value,
+		},
+		Result: &types.TraceOutput{Output: "0x" + hex.EncodeToString(response)},
+		Type:   "call",
+	}
+}
+
+// callTrace returns the trace of a call made by a contract.
+func callTrace(call event.CallEvent) types.Trace {
+	value := "0x" + strconv.FormatUint(call.Value, 16)
+
+	var trace types.Trace
+	if call.CallType == "create" {
+		trace = types.Trace{
+			Action: types.TraceAction{From: "0x" + call.Caller, Init: "0x" + call.Input, Value: value},
+			Result: &types.TraceOutput{Address: "0x" + call.Callee, Code: "0x" + call.Output},
+			Type:   "create",
+		}
+	} else {
+		trace = types.Trace{
+			Action: types.TraceAction{
+				CallType: call.CallType,
+				From:     "0x" + call.Caller,
+				To:       "0x" + call.Callee,
+				Input:    "0x" + call.Input,
+				Value:    value,
+			},
+			Result: &types.TraceOutput{Output: "0x" + call.Output},
+			Type:   "call",
+		}
+	}
+
+	if call.Error != "" {
+		trace.Result = nil
+		trace.Error = call.Error
+	}
+	return trace
+}
+
+// fabricEventToCalls returns the calls recorded in the chaincode event of a
+// transaction.
+func fabricEventToCalls(events []byte) ([]event.CallEvent, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	chaincodeEvent := &peer.ChaincodeEvent{}
+	if err := proto.Unmarshal(events, chaincodeEvent); err != nil {
+		return nil, errors.Wrap(err, "failed to decode chaincode event")
+	}
+
+	var payload event.Payload
+	if err := json.Unmarshal(chaincodeEvent.Payload, &payload); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal chaincode event payload")
+	}
+	return payload.Calls, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fab3_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+
+	"github.com/hyperledger/fabric-chaincode-evm/event"
+	"github.com/hyperledger/fabric-chaincode-evm/fab3"
+	"github.com/hyperledger/fabric-chaincode-evm/fab3/types"
+
+	fab3_mocks "github.com/hyperledger/fabric-chaincode-evm/mocks/fab3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Traceservice", func() {
+	var (
+		traceservice fab3.TraceService
+
+		mockLedgerClient *fab3_mocks.MockLedgerClient
+		txID             string
+		reply            []types.Trace
+	)
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.AddSync(GinkgoWriter), zap.DebugLevel)
+	logger := zap.New(core).Sugar()
+
+	chaincodeEvent := func(payload interface{}) []byte {
+		payloadBytes, err := json.Marshal(payload)
+		Expect(err).ToNot(HaveOccurred())
+
+		eventBytes, err := proto.Marshal(&peer.ChaincodeEvent{ChaincodeId: evmcc, TxId: txID, EventName: "Chaincode event", Payload: payloadBytes})
+		Expect(err).ToNot(HaveOccurred())
+		return eventBytes
+	}
+
+	returnTransaction := func(args [][]byte, response, events []byte) {
+		tx, err := GetSampleTransaction(args, response, events, txID)
+		Expect(err).ToNot(HaveOccurred())
+		mockLedgerClient.QueryBlockByTxIDReturns(GetSampleBlockWithTransaction(31, []byte("12345abcd"), tx), nil)
+	}
+
+	BeforeEach(func() {
+		mockLedgerClient = &fab3_mocks.MockLedgerClient{}
+		txID = "1234567123"
+		reply = nil
+
+		traceservice = fab3.NewTraceService(mockLedgerClient, logger)
+	})
+
+	Describe("Transaction", func() {
+		It("returns the call of the transaction followed by the calls of its contracts", func() {
+			// a calls b, which fails to create c, then a calls d
+			events := chaincodeEvent(event.Payload{
+				Logs: []event.Event{{Address: "82373458"}},
+				Calls: []event.CallEvent{
+					{CallType: "call", Caller: "82373458", Callee: "0b", Input: "01", Output: "02", Value: 3, Depth: 1},
+					{CallType: "create", Caller: "0b", Callee: "0c", Input: "6000", Depth: 2, Error: "execution reverted"},
+					{CallType: "staticcall", Caller: "82373458", Callee: "0d", Input: "04", Output: "05", Depth: 1},
+				},
+				Structured: true,
+			})
+			returnTransaction([][]byte{[]byte("82373458"), []byte("abcd"), []byte(""), []byte("16")}, []byte{0x2a}, events)
+
+			hash := "0x" + txID
+			err := traceservice.Transaction(&http.Request{}, &hash, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(HaveLen(4))
+
+			for _, trace := range reply {
+				Expect(trace.BlockNumber).To(Equal("0x1f"))
+				Expect(trace.BlockHash).To(HavePrefix("0x"))
+				Expect(trace.TransactionHash).To(Equal("0x" + txID))
+				Expect(trace.TransactionPosition).To(Equal("0x0"))
+			}
+
+			Expect(reply[0].Action).To(Equal(types.TraceAction{CallType: "call", From: addrFromCert, To: "0x82373458", Input: "0xabcd", Value: "0x10"}))
+			Expect(reply[0].Result).To(Equal(&types.TraceOutput{Output: "0x" + hex.EncodeToString([]byte{0x2a})}))
+			Expect(reply[0].Type).To(Equal("call"))
+			Expect(reply[0].Subtraces).To(Equal(2))
+			Expect(reply[0].TraceAddress).To(Equal([]int{}))
+
+			Expect(reply[1].Action).To(Equal(types.TraceAction{CallType: "call", From: "0x82373458", To: "0x0b", Input: "0x01", Value: "0x3"}))
+			Expect(reply[1].Result).To(Equal(&types.TraceOutput{Output: "0x02"}))
+			Expect(reply[1].Subtraces).To(Equal(1))
+			Expect(reply[1].TraceAddress).To(Equal([]int{0}))
+
+			Expect(reply[2].Action).To(Equal(types.TraceAction{From: "0x0b", Init: "0x6000", Value: "0x0"}))
+			Expect(reply[2].Result).To(BeNil())
+			Expect(reply[2].Error).To(Equal("execution reverted"))
+			Expect(reply[2].Type).To(Equal("create"))
+			Expect(reply[2].Subtraces).To(Equal(0))
+			Expect(reply[2].TraceAddress).To(Equal([]int{0, 0}))
+
+			Expect(reply[3].Action.CallType).To(Equal("staticcall"))
+			Expect(reply[3].Result).To(Equal(&types.TraceOutput{Output: "0x05"}))
+			Expect(reply[3].TraceAddress).To(Equal([]int{1}))
+		})
+
+		It("returns the deployment of a transaction without calls", func() {
+			events := chaincodeEvent([]event.Event{{Address: "82373458"}})
+			returnTransaction([][]byte{[]byte("0000000000000000000000000000000000000000"), []byte("6000")}, []byte("82373458"), events)
+
+			err := traceservice.Transaction(&http.Request{}, &txID, &reply)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reply).To(HaveLen(1))
+			Expect(reply[0].Action).To(Equal(types.TraceAction{From: addrFromCert, Init: "0x6000", Value: "0x0"}))
+			Expect(reply[0].Result).To(Equal(&types.TraceOutput{Address: "0x82373458"}))
+			Expect(reply[0].Type).To(Equal("create"))
+			Expect(reply[0].Subtraces).To(Equal(0))
+		})
+
+		It("returns an error when a call has an invalid depth", func() {
+			events := chaincodeEvent(event.Payload{Calls: []event.CallEvent{{CallType: "call", Depth: 2}}, Structured: true})
+			returnTransaction([][]byte{[]byte("82373458"), []byte("abcd")}, nil, events)
+
+			err := traceservice.Transaction(&http.Request{}, &txID, &reply)
+			Expect(err).To(MatchError("Call 0 of transaction 1234567123 has an invalid depth 2"))
+		})
+
+		It("returns an error when the transaction is not a call of a contract", func() {
+			returnTransaction([][]byte{[]byte("mint"), []byte("82373458"), []byte("100")}, []byte("100"), nil)
+
+			err := traceservice.Transaction(&http.Request{}, &txID, &reply)
+			Expect(err).To(MatchError("Transaction 1234567123 is not a call or deployment of a contract"))
+		})
+
+		It("returns an error when the ledger returns an error", func() {
+			mockLedgerClient.QueryBlockByTxIDReturns(nil, errors.New("bad ledger lookup"))
+
+			err := traceservice.Transaction(&http.Request{}, &txID, &reply)
+			Expect(err).To(MatchError("Failed to query the ledger: bad ledger lookup"))
+		})
+
+		It("returns an error when given an empty transaction hash", func() {
+			hash := ""
+			err := traceservice.Transaction(&http.Request{}, &hash, &reply)
+			Expect(err).To(MatchError("txID was empty"))
+		})
+	})
+})
@@ -38,6 +38,11 @@ type EthArgs struct {
 	Value    string `json:"value"`
 	Data     string `json:"data"`
 	Nonce    string `json:"nonce"`
+	// Transient is a Fab3 extension which holds hex input data that is sent
+	// in the transient map of the proposal, so it is not recorded in the
+	// ledger. The key "input" holds the whole input, when data is empty, and
+	// decimal byte offsets hold the parts of the input they replace.
+	Transient map[string]string `json:"transient,omitempty"`
 }
 
 type GetLogsArgs struct {
@@ -230,6 +235,84 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 	return json.Marshal(temp)
 }
 
+// Account describes an account of the EVM chaincode. It is returned by the
+// eth_getAccount extension, which is not part of the ethereum json-rpc.
+type Account struct {
+	Address     string   `json:"address"`            // DATA, 20 Bytes - address of the account.
+	Exists      bool     `json:"exists"`             // Boolean - whether the account is stored on the ledger.
+	Balance     string   `json:"balance"`            // QUANTITY - native balance of the account.
+	Sequence    string   `json:"sequence"`           // QUANTITY - nonce of the account.
+	CodeSize    string   `json:"codeSize"`           // QUANTITY - size of the runtime code in bytes.
+	CodeHash    string   `json:"codeHash,omitempty"` // DATA, 32 Bytes - keccak256 hash of the runtime code. Omitted when the account does not exist.
+	Permissions []string `json:"permissions"`        // Array - base permission flags set on the account.
+}
+
+// CallResult is the result of one of the calls of the eth_multicall
+// extension, which is not part of the ethereum json-rpc.
+type CallResult struct {
+	Success    bool   `json:"success"`            // Boolean - whether the call succeeded.
+	ReturnData string `json:"returnData"`         // DATA - output of the call, or the revert data of a reverted call.
+	Error      string `json:"error,omitempty"`    // String - error of a failed call.
+	Reverted   bool   `json:"reverted,omitempty"` // Boolean - whether the failed call was reverted by the contract.
+}
+
+// TraceResult is the result of debug_traceCall and debug_traceTransaction, in
+// the format of the struct logger of geth.
+type TraceResult struct {
+	Gas         uint64      `json:"gas"`              // QUANTITY - gas used by the call.
+	Failed      bool        `json:"failed"`           // Boolean - whether the call failed.
+	ReturnValue string      `json:"returnValue"`      // DATA - output of the call without 0x prefix, or the revert data of a reverted call.
+	Error       string      `json:"error,omitempty"`  // String - error of a failed call, a Fab3 extension.
+	StructLogs  []StructLog `json:"structLogs"`       // Array - steps of the execution of the call.
+	Writes      []string    `json:"writes,omitempty"` // Array - writes outside of the EVM state made through precompiles, a Fab3 extension.
+}
+
+// StructLog is the state of the EVM before it executed an opcode of a traced
+// call. Words are hex encoded without 0x prefix.
+type StructLog struct {
+	PC      uint64            `json:"pc"`                // QUANTITY - program counter.
+	Op      string            `json:"op"`                // String - name of the opcode.
+	Gas     uint64            `json:"gas"`               // QUANTITY - gas left before the opcode.
+	GasCost uint64            `json:"gasCost"`           // QUANTITY - gas used by the opcode.
+	Depth   uint64            `json:"depth"`             // QUANTITY - depth of the call, starting at 1.
+	Stack   []string          `json:"stack"`             // Array - words of the stack, the top of the stack last.
+	Memory  []string          `json:"memory"`            // Array - words of the memory.
+	Storage map[string]string `json:"storage,omitempty"` // Object - storage slots of the contract read or written so far, only set for SLOAD and SSTORE.
+}
+
+// Trace is a call of a transaction in the format of trace_transaction of
+// OpenEthereum: the call of the transaction itself, or an internal transaction
+// made by a contract. Values are hex encoded with 0x prefix.
+type Trace struct {
+	Action              TraceAction  `json:"action"`              // Object - the call.
+	Result              *TraceOutput `json:"result,omitempty"`    // Object - the result of the call, null when it failed.
+	Error               string       `json:"error,omitempty"`     // String - error of a failed call.
+	Subtraces           int          `json:"subtraces"`           // Number - number of calls made by the call.
+	TraceAddress        []int        `json:"traceAddress"`        // Array - indexes of the call in the calls made by each of its callers.
+	Type                string       `json:"type"`                // String - call or create.
+	BlockHash           string       `json:"blockHash"`           // DATA, 32 Bytes - hash of the block of the transaction.
+	BlockNumber         string       `json:"blockNumber"`         // QUANTITY - number of the block of the transaction.
+	TransactionHash     string       `json:"transactionHash"`     // DATA, 32 Bytes - hash of the transaction.
+	TransactionPosition string       `json:"transactionPosition"` // QUANTITY - index of the transaction in its block.
+}
+
+// TraceAction is the call of a Trace.
+type TraceAction struct {
+	CallType string `json:"callType,omitempty"` // String - call, callcode, delegatecall, staticcall, or native for the precompiles of evmcc. Not set for creates.
+	From     string `json:"from"`               // DATA, 20 Bytes - address of the caller.
+	To       string `json:"to,omitempty"`       // DATA, 20 Bytes - address of the callee. Not set for creates.
+	Input    string `json:"input,omitempty"`    // DATA - input of the call. Not set for creates.
+	Init     string `json:"init,omitempty"`     // DATA - init code of a created contract.
+	Value    string `json:"value"`              // QUANTITY - value transferred to the callee.
+}
+
+// TraceOutput is the result of a successful call of a Trace.
+type TraceOutput struct {
+	Output  string `json:"output,omitempty"`  // DATA - output of the call. Not set for creates.
+	Address string `json:"address,omitempty"` // DATA, 20 Bytes - address of a created contract.
+	Code    string `json:"code,omitempty"`    // DATA - runtime code of a created contract.
+}
+
 // Block is an eth return struct
 // defined https://github.com/ethereum/wiki/wiki/JSON-RPC#returns-26
 type Block struct {
@@ -243,6 +326,7 @@ type BlockData struct {
 	Hash       string `json:"hash"`       // hash: DATA, 32 Bytes - hash of the block. null when its pending block.
 	ParentHash string `json:"parentHash"` // parentHash: DATA, 32 Bytes - hash of the parent block.
 	GasLimit   string `json:"gasLimit"`   // gasLimit: QUANTITY - the maximum gas allowed in this block.
+	GasUsed    string `json:"gasUsed"`    // gasUsed: QUANTITY - the total used gas by all transactions in this block.
 }
 
 // MarshalJSON marshals the data differently based on whether
@@ -54,7 +54,7 @@ var _ = Describe("EndToEnd", func() {
 			Name:    "evmcc",
 			Version: "0.0",
 			Path:    "github.com/hyperledger/fabric-chaincode-evm/evmcc",
-			Ctor:    `{"Args":[]}`,
+			Ctor:    `{"Args":["blockcounter=false"]}`,
 			Policy:  `AND ('Org1MSP.member')`,
 		}
 		network = nwo.New(helpers.SimpleSoloNetwork(), testDir, client, 30000, components)
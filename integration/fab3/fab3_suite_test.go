@@ -79,7 +79,7 @@ var _ = SynchronizedBeforeSuite(func() []byte {
 		Name:    ccid,
 		Version: "0.0",
 		Path:    "github.com/hyperledger/fabric-chaincode-evm/evmcc",
-		Ctor:    `{"Args":[]}`,
+		Ctor:    `{"Args":["blockcounter=false"]}`,
 		Policy:  `AND ('Org1MSP.member')`,
 	}
 	nwo.DeployChaincode(network, channelName, orderer, chaincode)
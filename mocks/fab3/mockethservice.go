@@ -62,6 +62,19 @@ type MockEthService struct {
 	estimateGasReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetAccountStub        func(*http.Request, *[]string, *types.Account) error
+	getAccountMutex       sync.RWMutex
+	getAccountArgsForCall []struct {
+		arg1 *http.Request
+		arg2 *[]string
+		arg3 *types.Account
+	}
+	getAccountReturns struct {
+		result1 error
+	}
+	getAccountReturnsOnCall map[int]struct {
+		result1 error
+	}
 	GetBalanceStub        func(*http.Request, *[]string, *string) error
 	getBalanceMutex       sync.RWMutex
 	getBalanceArgsForCall []struct {
@@ -114,6 +127,19 @@ type MockEthService struct {
 	getLogsReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetStorageAtStub        func(*http.Request, *[]string, *string) error
+	getStorageAtMutex       sync.RWMutex
+	getStorageAtArgsForCall []struct {
+		arg1 *http.Request
+		arg2 *[]string
+		arg3 *string
+	}
+	getStorageAtReturns struct {
+		result1 error
+	}
+	getStorageAtReturnsOnCall map[int]struct {
+		result1 error
+	}
 	GetTransactionByHashStub        func(*http.Request, *string, *types.Transaction) error
 	getTransactionByHashMutex       sync.RWMutex
 	getTransactionByHashArgsForCall []struct {
@@ -127,11 +153,11 @@ type MockEthService struct {
 	getTransactionByHashReturnsOnCall map[int]struct {
 		result1 error
 	}
-	GetTransactionCountStub        func(*http.Request, *interface{}, *string) error
+	GetTransactionCountStub        func(*http.Request, *[]string, *string) error
 	getTransactionCountMutex       sync.RWMutex
 	getTransactionCountArgsForCall []struct {
 		arg1 *http.Request
-		arg2 *interface{}
+		arg2 *[]string
 		arg3 *string
 	}
 	getTransactionCountReturns struct {
@@ -153,6 +179,19 @@ type MockEthService struct {
 	getTransactionReceiptReturnsOnCall map[int]struct {
 		result1 error
 	}
+	MulticallStub        func(*http.Request, *[]types.EthArgs, *[]types.CallResult) error
+	multicallMutex       sync.RWMutex
+	multicallArgsForCall []struct {
+		arg1 *http.Request
+		arg2 *[]types.EthArgs
+		arg3 *[]types.CallResult
+	}
+	multicallReturns struct {
+		result1 error
+	}
+	multicallReturnsOnCall map[int]struct {
+		result1 error
+	}
 	NewFilterStub        func(*http.Request, *types.GetLogsArgs, *string) error
 	newFilterMutex       sync.RWMutex
 	newFilterArgsForCall []struct {
@@ -404,6 +443,58 @@ func (fake *MockEthService) EstimateGasReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *MockEthService) GetAccount(arg1 *http.Request, arg2 *[]string, arg3 *types.Account) error {
+	fake.getAccountMutex.Lock()
+	ret, specificReturn := fake.getAccountReturnsOnCall[len(fake.getAccountArgsForCall)]
+	fake.getAccountArgsForCall = append(fake.getAccountArgsForCall, struct {
+		arg1 *http.Request
+		arg2 *[]string
+		arg3 *types.Account
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("GetAccount", []interface{}{arg1, arg2, arg3})
+	fake.getAccountMutex.Unlock()
+	if fake.GetAccountStub != nil {
+		return fake.GetAccountStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.getAccountReturns
+	return fakeReturns.result1
+}
+
+func (fake *MockEthService) GetAccountCallCount() int {
+	fake.getAccountMutex.RLock()
+	defer fake.getAccountMutex.RUnlock()
+	return len(fake.getAccountArgsForCall)
+}
+
+func (fake *MockEthService) GetAccountArgsForCall(i int) (*http.Request, *[]string, *types.Account) {
+	fake.getAccountMutex.RLock()
+	defer fake.getAccountMutex.RUnlock()
+	argsForCall := fake.getAccountArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *MockEthService) GetAccountReturns(result1 error) {
+	fake.GetAccountStub = nil
+	fake.getAccountReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *MockEthService) GetAccountReturnsOnCall(i int, result1 error) {
+	fake.GetAccountStub = nil
+	if fake.getAccountReturnsOnCall == nil {
+		fake.getAccountReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getAccountReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *MockEthService) GetBalance(arg1 *http.Request, arg2 *[]string, arg3 *string) error {
 	fake.getBalanceMutex.Lock()
 	ret, specificReturn := fake.getBalanceReturnsOnCall[len(fake.getBalanceArgsForCall)]
@@ -612,6 +703,58 @@ func (fake *MockEthService) GetLogsReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *MockEthService) GetStorageAt(arg1 *http.Request, arg2 *[]string, arg3 *string) error {
+	fake.getStorageAtMutex.Lock()
+	ret, specificReturn := fake.getStorageAtReturnsOnCall[len(fake.getStorageAtArgsForCall)]
+	fake.getStorageAtArgsForCall = append(fake.getStorageAtArgsForCall, struct {
+		arg1 *http.Request
+		arg2 *[]string
+		arg3 *string
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("GetStorageAt", []interface{}{arg1, arg2, arg3})
+	fake.getStorageAtMutex.Unlock()
+	if fake.GetStorageAtStub != nil {
+		return fake.GetStorageAtStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.getStorageAtReturns
+	return fakeReturns.result1
+}
+
+func (fake *MockEthService) GetStorageAtCallCount() int {
+	fake.getStorageAtMutex.RLock()
+	defer fake.getStorageAtMutex.RUnlock()
+	return len(fake.getStorageAtArgsForCall)
+}
+
+func (fake *MockEthService) GetStorageAtArgsForCall(i int) (*http.Request, *[]string, *string) {
+	fake.getStorageAtMutex.RLock()
+	defer fake.getStorageAtMutex.RUnlock()
+	argsForCall := fake.getStorageAtArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *MockEthService) GetStorageAtReturns(result1 error) {
+	fake.GetStorageAtStub = nil
+	fake.getStorageAtReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *MockEthService) GetStorageAtReturnsOnCall(i int, result1 error) {
+	fake.GetStorageAtStub = nil
+	if fake.getStorageAtReturnsOnCall == nil {
+		fake.getStorageAtReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getStorageAtReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *MockEthService) GetTransactionByHash(arg1 *http.Request, arg2 *string, arg3 *types.Transaction) error {
 	fake.getTransactionByHashMutex.Lock()
 	ret, specificReturn := fake.getTransactionByHashReturnsOnCall[len(fake.getTransactionByHashArgsForCall)]
@@ -664,12 +807,12 @@ func (fake *MockEthService) GetTransactionByHashReturnsOnCall(i int, result1 err
 	}{result1}
 }
 
-func (fake *MockEthService) GetTransactionCount(arg1 *http.Request, arg2 *interface{}, arg3 *string) error {
+func (fake *MockEthService) GetTransactionCount(arg1 *http.Request, arg2 *[]string, arg3 *string) error {
 	fake.getTransactionCountMutex.Lock()
 	ret, specificReturn := fake.getTransactionCountReturnsOnCall[len(fake.getTransactionCountArgsForCall)]
 	fake.getTransactionCountArgsForCall = append(fake.getTransactionCountArgsForCall, struct {
 		arg1 *http.Request
-		arg2 *interface{}
+		arg2 *[]string
 		arg3 *string
 	}{arg1, arg2, arg3})
 	fake.recordInvocation("GetTransactionCount", []interface{}{arg1, arg2, arg3})
@@ -690,7 +833,7 @@ func (fake *MockEthService) GetTransactionCountCallCount() int {
 	return len(fake.getTransactionCountArgsForCall)
 }
 
-func (fake *MockEthService) GetTransactionCountArgsForCall(i int) (*http.Request, *interface{}, *string) {
+func (fake *MockEthService) GetTransactionCountArgsForCall(i int) (*http.Request, *[]string, *string) {
 	fake.getTransactionCountMutex.RLock()
 	defer fake.getTransactionCountMutex.RUnlock()
 	argsForCall := fake.getTransactionCountArgsForCall[i]
@@ -768,6 +911,58 @@ func (fake *MockEthService) GetTransactionReceiptReturnsOnCall(i int, result1 er
 	}{result1}
 }
 
+func (fake *MockEthService) Multicall(arg1 *http.Request, arg2 *[]types.EthArgs, arg3 *[]types.CallResult) error {
+	fake.multicallMutex.Lock()
+	ret, specificReturn := fake.multicallReturnsOnCall[len(fake.multicallArgsForCall)]
+	fake.multicallArgsForCall = append(fake.multicallArgsForCall, struct {
+		arg1 *http.Request
+		arg2 *[]types.EthArgs
+		arg3 *[]types.CallResult
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("Multicall", []interface{}{arg1, arg2, arg3})
+	fake.multicallMutex.Unlock()
+	if fake.MulticallStub != nil {
+		return fake.MulticallStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.multicallReturns
+	return fakeReturns.result1
+}
+
+func (fake *MockEthService) MulticallCallCount() int {
+	fake.multicallMutex.RLock()
+	defer fake.multicallMutex.RUnlock()
+	return len(fake.multicallArgsForCall)
+}
+
+func (fake *MockEthService) MulticallArgsForCall(i int) (*http.Request, *[]types.EthArgs, *[]types.CallResult) {
+	fake.multicallMutex.RLock()
+	defer fake.multicallMutex.RUnlock()
+	argsForCall := fake.multicallArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *MockEthService) MulticallReturns(result1 error) {
+	fake.MulticallStub = nil
+	fake.multicallReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *MockEthService) MulticallReturnsOnCall(i int, result1 error) {
+	fake.MulticallStub = nil
+	if fake.multicallReturnsOnCall == nil {
+		fake.multicallReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.multicallReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *MockEthService) NewFilter(arg1 *http.Request, arg2 *types.GetLogsArgs, arg3 *string) error {
 	fake.newFilterMutex.Lock()
 	ret, specificReturn := fake.newFilterReturnsOnCall[len(fake.newFilterArgsForCall)]
@@ -935,6 +1130,8 @@ func (fake *MockEthService) Invocations() map[string][][]interface{} {
 	defer fake.callMutex.RUnlock()
 	fake.estimateGasMutex.RLock()
 	defer fake.estimateGasMutex.RUnlock()
+	fake.getAccountMutex.RLock()
+	defer fake.getAccountMutex.RUnlock()
 	fake.getBalanceMutex.RLock()
 	defer fake.getBalanceMutex.RUnlock()
 	fake.getBlockByNumberMutex.RLock()
@@ -943,12 +1140,16 @@ func (fake *MockEthService) Invocations() map[string][][]interface{} {
 	defer fake.getCodeMutex.RUnlock()
 	fake.getLogsMutex.RLock()
 	defer fake.getLogsMutex.RUnlock()
+	fake.getStorageAtMutex.RLock()
+	defer fake.getStorageAtMutex.RUnlock()
 	fake.getTransactionByHashMutex.RLock()
 	defer fake.getTransactionByHashMutex.RUnlock()
 	fake.getTransactionCountMutex.RLock()
 	defer fake.getTransactionCountMutex.RUnlock()
 	fake.getTransactionReceiptMutex.RLock()
 	defer fake.getTransactionReceiptMutex.RUnlock()
+	fake.multicallMutex.RLock()
+	defer fake.multicallMutex.RUnlock()
 	fake.newFilterMutex.RLock()
 	defer fake.newFilterMutex.RUnlock()
 	fake.sendTransactionMutex.RLock()
@@ -16,6 +16,7 @@ function filterExcludedFiles {
 		| grep -v "^\.git/" \
 		| grep -v "^\.build/" \
 		| grep -v "^vendor/" \
+		| grep -v "^third_party/" \
 		| grep -v "testdata/" \
 		| grep -v "release_notes/" \
 		| grep -v "^LICENSE$" \
@@ -6,12 +6,12 @@
 #
 
 CHECK=$(git diff --name-only HEAD * | grep -v .png$ | grep -v .git | grep -v ^CHANGELOG \
-  | grep -v ^vendor/ | grep -v ^build/ | sort -u)
+  | grep -v ^vendor/ | grep -v ^third_party/ | grep -v ^build/ | sort -u)
 
 if [[ -z "$CHECK" ]]; then
   CHECK=$(git diff-tree --no-commit-id --name-only -r $(git log -2 \
     --pretty=format:"%h") | grep -v .png$ | grep -v .git | grep -v ^CHANGELOG \
-    | grep -v ^vendor/ | grep -v ^build/ | sort -u)
+    | grep -v ^vendor/ | grep -v ^third_party/ | grep -v ^build/ | sort -u)
 fi
 
 echo "Checking changed go files for spelling errors ..."
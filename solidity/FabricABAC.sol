@@ -0,0 +1,42 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+pragma solidity >=0.5.0 <0.7.0;
+pragma experimental ABIEncoderV2;
+
+/**
+ * Interface of the evmcc precompile through which contracts check the identity
+ * which submitted the Fabric transaction, for attribute based access control.
+ * Attributes are the ones the Fabric CA stores in the certificate of the
+ * identity.
+ *
+ * FabricABAC constant fabricABAC = FabricABAC(0x000000000000000000000000000000000000fab3);
+ */
+interface FabricABAC {
+    /**
+     * @param name the name of the attribute, such as "role"
+     * @param value the value of the attribute, such as "auditor"
+     * @return whether the certificate of the caller holds the attribute with the value
+     */
+    function hasAttribute(string calldata name, string calldata value) external view returns (bool);
+
+    /**
+     * @param name the name of the attribute
+     * @return value the value of the attribute, empty when it is not found
+     * @return found whether the certificate of the caller holds the attribute
+     */
+    function getAttribute(string calldata name) external view returns (string memory value, bool found);
+
+    /**
+     * @return the MSP ID of the caller
+     */
+    function callerMSPID() external view returns (string memory);
+
+    /**
+     * @return the organizational units of the certificate of the caller
+     */
+    function callerOUs() external view returns (string[] memory);
+}
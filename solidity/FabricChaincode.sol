@@ -0,0 +1,33 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+pragma solidity >=0.5.0 <0.7.0;
+pragma experimental ABIEncoderV2;
+
+/**
+ * Interface of the evmcc precompile through which contracts invoke other
+ * chaincodes, as a Go chaincode does with stub.InvokeChaincode.
+ *
+ * FabricChaincode constant fabricChaincode = FabricChaincode(0x000000000000000000000000000000000000fab1);
+ */
+interface FabricChaincode {
+    /**
+     * Invokes a chaincode on a channel, or on the channel of the transaction
+     * when channel is empty. Chaincodes on other channels can only be queried.
+     * Contracts which are paused cannot invoke chaincodes. As the invocation
+     * cannot be undone, the transaction fails when a call which led to it
+     * reverts, even if the revert is caught.
+     * @param name the name of the chaincode
+     * @param args the arguments of the invocation, starting with the function
+     * @param channel the channel of the chaincode
+     * @return status the status of the response, 200 when it succeeded
+     * @return payload the payload of the response
+     * @return message the message of the response, set when it failed
+     */
+    function invokeChaincode(string calldata name, bytes[] calldata args, string calldata channel)
+        external
+        returns (int32 status, bytes memory payload, string memory message);
+}
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+pragma solidity >=0.5.0 <0.7.0;
+pragma experimental ABIEncoderV2;
+
+/**
+ * Interface of the evmcc precompile through which contracts set key-level
+ * endorsement policies on the world state keys of their account and storage
+ * slots. Once set, transactions which write the keys must be endorsed as the
+ * policy requires, instead of as the endorsement policy of the chaincode
+ * requires. Contracts can only set the policies of their own keys, and
+ * contracts which are paused cannot set policies. A policy is not set when a
+ * call which led to setting it reverts.
+ *
+ * FabricEndorsement constant fabricEndorsement = FabricEndorsement(0x000000000000000000000000000000000000fab5);
+ */
+interface FabricEndorsement {
+    /**
+     * Requires an endorsement from a peer of each of the organizations to
+     * write the account of the contract, which holds its balance and code.
+     * An empty list removes the policy.
+     * @param mspIDs the MSP IDs of the organizations
+     */
+    function setAccountEndorsers(string[] calldata mspIDs) external;
+
+    /**
+     * Requires an endorsement from a peer of each of the organizations to
+     * write a storage slot of the contract. An empty list removes the policy.
+     * @param slot the storage slot
+     * @param mspIDs the MSP IDs of the organizations
+     */
+    function setStorageEndorsers(bytes32 slot, string[] calldata mspIDs) external;
+
+    /**
+     * Sets the validation parameter of the account of the contract, which is
+     * a serialized Fabric SignaturePolicyEnvelope.
+     */
+    function setAccountValidationParameter(bytes calldata ep) external;
+
+    /**
+     * Sets the validation parameter of a storage slot of the contract, which
+     * is a serialized Fabric SignaturePolicyEnvelope.
+     */
+    function setStorageValidationParameter(bytes32 slot, bytes calldata ep) external;
+
+    /**
+     * @return the validation parameter of the account of the contract, empty when it has none
+     */
+    function getAccountValidationParameter() external view returns (bytes memory);
+
+    /**
+     * @return the validation parameter of a storage slot of the contract, empty when it has none
+     */
+    function getStorageValidationParameter(bytes32 slot) external view returns (bytes memory);
+}
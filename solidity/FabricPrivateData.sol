@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+pragma solidity >=0.5.0 <0.7.0;
+
+/**
+ * Interface of the evmcc precompile through which contracts read and write
+ * the private data collections of the channel. The keys of a contract are
+ * stored in the collection prefixed with the lowercase hex address of the
+ * contract and a colon, so contracts only see their own private data.
+ *
+ * As in Fabric, reads do not return the writes of the same transaction.
+ *
+ * FabricPrivateData constant fabricPrivateData = FabricPrivateData(0x000000000000000000000000000000000000fab4);
+ */
+interface FabricPrivateData {
+    /**
+     * @return the value of the key in the collection, empty when it has none
+     */
+    function getPrivateData(string calldata collection, string calldata key) external view returns (bytes memory);
+
+    /**
+     * Sets the value of the key in the collection. Contracts which are
+     * paused cannot write private data. The write is dropped when a call
+     * which led to it reverts.
+     */
+    function putPrivateData(string calldata collection, string calldata key, bytes calldata value) external;
+
+    /**
+     * Deletes the key from the collection. Contracts which are paused cannot
+     * write private data. The deletion is dropped when a call which led to it
+     * reverts.
+     */
+    function delPrivateData(string calldata collection, string calldata key) external;
+
+    /**
+     * Peers which are not members of the collection can read the hash as
+     * well. It needs a Fabric version whose chaincode shim provides
+     * GetPrivateDataHash.
+     * @return the hash of the value of the key in the collection, zero when it has none
+     */
+    function getPrivateDataHash(string calldata collection, string calldata key) external view returns (bytes32);
+}
@@ -0,0 +1,37 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+pragma solidity >=0.5.0 <0.7.0;
+
+/**
+ * Interface of the evmcc precompile through which contracts read the context
+ * of the Fabric transaction. The values are taken from the transaction
+ * proposal, so every endorser returns the same values.
+ *
+ * FabricTxContext constant fabricTxContext = FabricTxContext(0x000000000000000000000000000000000000fab2);
+ */
+interface FabricTxContext {
+    /**
+     * @return the ID of the transaction
+     */
+    function txID() external view returns (string memory);
+
+    /**
+     * @return seconds the seconds of the timestamp the client set in the proposal
+     * @return nanos the nanoseconds of the timestamp
+     */
+    function txTimestamp() external view returns (int64 seconds, int32 nanos);
+
+    /**
+     * @return the ID of the channel of the transaction
+     */
+    function channelID() external view returns (string memory);
+
+    /**
+     * @return the MSP ID of the identity which submitted the transaction
+     */
+    function creatorMSPID() external view returns (string memory);
+}
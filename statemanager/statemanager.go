@@ -16,6 +16,10 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 )
 
+// storageKeyRangeEnd follows every lowercase hex digit, so appending it to an
+// account key gives the end of the range of its storage keys.
+const storageKeyRangeEnd = "g"
+
 type StateManager interface {
 	GetAccount(address crypto.Address) (*acm.Account, error)
 	GetStorage(address crypto.Address, key binary.Word256) (binary.Word256, error)
@@ -31,6 +35,18 @@ type stateManager struct {
 	cache map[string]binary.Word256
 }
 
+// AccountKey returns the world state key of an account, which is its lowercase
+// hex address.
+func AccountKey(address crypto.Address) string {
+	return strings.ToLower(address.String())
+}
+
+// StorageKey returns the world state key of a storage slot of an account,
+// which is the account key followed by the hex encoded slot.
+func StorageKey(address crypto.Address, key binary.Word256) string {
+	return AccountKey(address) + hex.EncodeToString(key.Bytes())
+}
+
 func NewStateManager(stub shim.ChaincodeStubInterface) StateManager {
 	return &stateManager{
 		stub:  stub,
@@ -39,7 +55,7 @@ func NewStateManager(stub shim.ChaincodeStubInterface) StateManager {
 }
 
 func (s *stateManager) GetAccount(address crypto.Address) (*acm.Account, error) {
-	acctBytes, err := s.stub.GetState(strings.ToLower(address.String()))
+	acctBytes, err := s.stub.GetState(AccountKey(address))
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +68,7 @@ func (s *stateManager) GetAccount(address crypto.Address) (*acm.Account, error)
 }
 
 func (s *stateManager) GetStorage(address crypto.Address, key binary.Word256) (binary.Word256, error) {
-	compKey := strings.ToLower(address.String()) + hex.EncodeToString(key.Bytes())
+	compKey := StorageKey(address, key)
 
 	if val, ok := s.cache[compKey]; ok {
 		return val, nil
@@ -71,15 +87,46 @@ func (s *stateManager) UpdateAccount(updatedAccount *acm.Account) error {
 	if err != nil {
 		return err
 	}
-	return s.stub.PutState(hex.EncodeToString(updatedAccount.Address.Bytes()), encodedAcct)
+	return s.stub.PutState(AccountKey(updatedAccount.Address), encodedAcct)
 }
 
+// RemoveAccount deletes the account along with all of its storage. Storage
+// keys are the account key followed by the hex encoded storage key, so they
+// are enumerated with a range query from the account key up to the first key
+// which is not prefixed by it.
 func (s *stateManager) RemoveAccount(address crypto.Address) error {
-	return s.stub.DelState(strings.ToLower(address.String()))
+	acctKey := AccountKey(address)
+
+	iter, err := s.stub.GetStateByRange(acctKey, acctKey+storageKeyRangeEnd)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	var storageKeys []string
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return err
+		}
+
+		if kv.Key != acctKey {
+			storageKeys = append(storageKeys, kv.Key)
+		}
+	}
+
+	for _, key := range storageKeys {
+		if err := s.stub.DelState(key); err != nil {
+			return err
+		}
+		delete(s.cache, key)
+	}
+
+	return s.stub.DelState(acctKey)
 }
 
 func (s *stateManager) SetStorage(address crypto.Address, key, value binary.Word256) error {
-	compKey := strings.ToLower(address.String()) + hex.EncodeToString(key.Bytes())
+	compKey := StorageKey(address, key)
 
 	var err error
 	if value == binary.Zero256 {
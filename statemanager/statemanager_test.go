@@ -9,12 +9,15 @@ package statemanager_test
 import (
 	"encoding/hex"
 	"errors"
+	"sort"
 
 	"github.com/hyperledger/burrow/acm"
 	"github.com/hyperledger/burrow/binary"
 	"github.com/hyperledger/burrow/crypto"
 	"github.com/hyperledger/fabric-chaincode-evm/mocks/evmcc"
 	"github.com/hyperledger/fabric-chaincode-evm/statemanager"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -55,6 +58,25 @@ var _ = Describe("Statemanager", func() {
 			delete(fakePutLedger, key)
 			return nil
 		}
+
+		mockStub.GetStateByRangeStub = func(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+			var kvs []*queryresult.KV
+			for key, value := range fakeGetLedger {
+				if key >= startKey && key < endKey {
+					kvs = append(kvs, &queryresult.KV{Key: key, Value: value})
+				}
+			}
+			sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+			iter := &evmcc.MockStateQueryIterator{}
+			iter.HasNextStub = func() bool { return len(kvs) > 0 }
+			iter.NextStub = func() (*queryresult.KV, error) {
+				kv := kvs[0]
+				kvs = kvs[1:]
+				return kv, nil
+			}
+			return iter, nil
+		}
 	})
 
 	Describe("GetAccount", func() {
@@ -232,6 +254,85 @@ var _ = Describe("Statemanager", func() {
 			})
 		})
 
+		Context("when the account has storage", func() {
+			var otherAddr crypto.Address
+
+			BeforeEach(func() {
+				var err error
+				otherAddr, err = crypto.AddressFromBytes([]byte("0000000000000addresz"))
+				Expect(err).ToNot(HaveOccurred())
+
+				fakeGetLedger[addr.String()] = []byte("account code")
+				fakeGetLedger[addr.String()+hex.EncodeToString(binary.LeftPadWord256([]byte("key1")).Bytes())] = []byte("value1")
+				fakeGetLedger[addr.String()+hex.EncodeToString(binary.LeftPadWord256([]byte("key2")).Bytes())] = []byte("value2")
+				fakeGetLedger[otherAddr.String()] = []byte("other account code")
+				fakeGetLedger[otherAddr.String()+hex.EncodeToString(binary.LeftPadWord256([]byte("key1")).Bytes())] = []byte("other value1")
+			})
+
+			It("removes the account and all of its storage", func() {
+				err := sm.RemoveAccount(addr)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(mockStub.GetStateByRangeCallCount()).To(Equal(1))
+				startKey, endKey := mockStub.GetStateByRangeArgsForCall(0)
+				Expect(startKey).To(Equal(addr.String()))
+				Expect(endKey).To(Equal(addr.String() + "g"))
+
+				Expect(mockStub.DelStateCallCount()).To(Equal(3))
+				Expect(mockStub.DelStateArgsForCall(0)).To(Equal(addr.String() + hex.EncodeToString(binary.LeftPadWord256([]byte("key1")).Bytes())))
+				Expect(mockStub.DelStateArgsForCall(1)).To(Equal(addr.String() + hex.EncodeToString(binary.LeftPadWord256([]byte("key2")).Bytes())))
+				Expect(mockStub.DelStateArgsForCall(2)).To(Equal(addr.String()))
+			})
+
+			It("forgets storage cached before the account was removed", func() {
+				key := binary.LeftPadWord256([]byte("key1"))
+				err := sm.SetStorage(addr, key, binary.LeftPadWord256([]byte("cached value")))
+				Expect(err).ToNot(HaveOccurred())
+
+				// The storage write is visible to the range query once it is committed
+				fakeGetLedger[addr.String()+hex.EncodeToString(key.Bytes())] = []byte("cached value")
+				err = sm.RemoveAccount(addr)
+				Expect(err).ToNot(HaveOccurred())
+				delete(fakeGetLedger, addr.String()+hex.EncodeToString(key.Bytes()))
+
+				val, err := sm.GetStorage(addr, key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(val).To(Equal(binary.Zero256))
+			})
+		})
+
+		Context("when the range query fails", func() {
+			BeforeEach(func() {
+				mockStub.GetStateByRangeReturns(nil, errors.New("boom!"))
+				mockStub.GetStateByRangeStub = nil
+			})
+
+			It("returns an error and does not remove the account", func() {
+				err := sm.RemoveAccount(addr)
+				Expect(err).To(MatchError("boom!"))
+				Expect(mockStub.DelStateCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the range query iterator fails", func() {
+			var iter *evmcc.MockStateQueryIterator
+
+			BeforeEach(func() {
+				iter = &evmcc.MockStateQueryIterator{}
+				iter.HasNextReturns(true)
+				iter.NextReturns(nil, errors.New("boom!"))
+				mockStub.GetStateByRangeStub = nil
+				mockStub.GetStateByRangeReturns(iter, nil)
+			})
+
+			It("returns an error and closes the iterator", func() {
+				err := sm.RemoveAccount(addr)
+				Expect(err).To(MatchError("boom!"))
+				Expect(mockStub.DelStateCallCount()).To(Equal(0))
+				Expect(iter.CloseCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("when the account did not exists previously", func() {
 			It("does not return an error", func() {
 				err := sm.RemoveAccount(addr)
@@ -0,0 +1,394 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asm
+
+import (
+	"fmt"
+)
+
+type OpCode byte
+
+const (
+	// Op codes
+	// 0x0 range - arithmetic ops
+	STOP OpCode = iota
+	ADD
+	MUL
+	SUB
+	DIV
+	SDIV
+	MOD
+	SMOD
+	ADDMOD
+	MULMOD
+	EXP
+	SIGNEXTEND
+)
+
+const (
+	LT OpCode = iota + 0x10
+	GT
+	SLT
+	SGT
+	EQ
+	ISZERO
+	AND
+	OR
+	XOR
+	NOT
+	BYTE
+	SHL
+	SHR
+	SAR
+
+	SHA3 = 0x20
+)
+
+const (
+	// 0x30 range - closure state
+	ADDRESS OpCode = 0x30 + iota
+	BALANCE
+	ORIGIN
+	CALLER
+	CALLVALUE
+	CALLDATALOAD
+	CALLDATASIZE
+	CALLDATACOPY
+	CODESIZE
+	CODECOPY
+	GASPRICE_DEPRECATED
+	EXTCODESIZE
+	EXTCODECOPY
+	RETURNDATASIZE
+	RETURNDATACOPY
+	EXTCODEHASH // https://github.com/ethereum/EIPs/blob/master/EIPS/eip-1052.md
+)
+
+const (
+	// 0x40 range - block operations
+	BLOCKHASH OpCode = 0x40 + iota
+	COINBASE
+	TIMESTAMP
+	BLOCKHEIGHT
+	DIFFICULTY_DEPRECATED
+	GASLIMIT
+	CHAINID
+	SELFBALANCE
+)
+
+const (
+	// 0x50 range - 'storage' and execution
+	POP OpCode = 0x50 + iota
+	MLOAD
+	MSTORE
+	MSTORE8
+	SLOAD
+	SSTORE
+	JUMP
+	JUMPI
+	PC
+	MSIZE
+	GAS
+	JUMPDEST
+)
+
+const (
+	// 0x60 range
+	PUSH1 OpCode = 0x60 + iota
+	PUSH2
+	PUSH3
+	PUSH4
+	PUSH5
+	PUSH6
+	PUSH7
+	PUSH8
+	PUSH9
+	PUSH10
+	PUSH11
+	PUSH12
+	PUSH13
+	PUSH14
+	PUSH15
+	PUSH16
+	PUSH17
+	PUSH18
+	PUSH19
+	PUSH20
+	PUSH21
+	PUSH22
+	PUSH23
+	PUSH24
+	PUSH25
+	PUSH26
+	PUSH27
+	PUSH28
+	PUSH29
+	PUSH30
+	PUSH31
+	PUSH32
+	DUP1
+	DUP2
+	DUP3
+	DUP4
+	DUP5
+	DUP6
+	DUP7
+	DUP8
+	DUP9
+	DUP10
+	DUP11
+	DUP12
+	DUP13
+	DUP14
+	DUP15
+	DUP16
+	SWAP1
+	SWAP2
+	SWAP3
+	SWAP4
+	SWAP5
+	SWAP6
+	SWAP7
+	SWAP8
+	SWAP9
+	SWAP10
+	SWAP11
+	SWAP12
+	SWAP13
+	SWAP14
+	SWAP15
+	SWAP16
+)
+
+const (
+	LOG0 OpCode = 0xa0 + iota
+	LOG1
+	LOG2
+	LOG3
+	LOG4
+)
+
+const (
+	// 0xf0 range - closures
+	CREATE OpCode = 0xf0 + iota
+	CALL
+	CALLCODE
+	RETURN
+	DELEGATECALL
+
+	// 0x70 range - other
+	STATICCALL   = 0xfa
+	CREATE2      = 0xfb
+	REVERT       = 0xfd
+	INVALID      = 0xfe
+	SELFDESTRUCT = 0xff
+)
+
+// EIP1014_CREATE2 is CREATE2 as specified by EIP-1014, which is enabled with the
+// EIP1014 VM option
+const EIP1014_CREATE2 OpCode = 0xf5
+
+var opCodeNames = map[OpCode]string{
+	// 0x0 range - arithmetic ops
+	STOP:       "STOP",
+	ADD:        "ADD",
+	MUL:        "MUL",
+	SUB:        "SUB",
+	DIV:        "DIV",
+	SDIV:       "SDIV",
+	MOD:        "MOD",
+	SMOD:       "SMOD",
+	EXP:        "EXP",
+	NOT:        "NOT",
+	LT:         "LT",
+	GT:         "GT",
+	SLT:        "SLT",
+	SGT:        "SGT",
+	EQ:         "EQ",
+	ISZERO:     "ISZERO",
+	SIGNEXTEND: "SIGNEXTEND",
+
+	// 0x10 range - bit ops
+	AND:    "AND",
+	OR:     "OR",
+	XOR:    "XOR",
+	BYTE:   "BYTE",
+	SHL:    "SHL",
+	SHR:    "SHR",
+	SAR:    "SAR",
+	ADDMOD: "ADDMOD",
+	MULMOD: "MULMOD",
+
+	// 0x20 range - crypto
+	SHA3: "SHA3",
+
+	// 0x30 range - closure state
+	ADDRESS:             "ADDRESS",
+	BALANCE:             "BALANCE",
+	ORIGIN:              "ORIGIN",
+	CALLER:              "CALLER",
+	CALLVALUE:           "CALLVALUE",
+	CALLDATALOAD:        "CALLDATALOAD",
+	CALLDATASIZE:        "CALLDATASIZE",
+	CALLDATACOPY:        "CALLDATACOPY",
+	CODESIZE:            "CODESIZE",
+	CODECOPY:            "CODECOPY",
+	GASPRICE_DEPRECATED: "TXGASPRICE_DEPRECATED",
+	EXTCODESIZE:         "EXTCODESIZE",
+	EXTCODECOPY:         "EXTCODECOPY",
+	RETURNDATASIZE:      "RETURNDATASIZE",
+	RETURNDATACOPY:      "RETURNDATACOPY",
+	EXTCODEHASH:         "EXTCODEHASH",
+
+	// 0x40 range - block operations
+	BLOCKHASH:             "BLOCKHASH",
+	COINBASE:              "COINBASE",
+	TIMESTAMP:             "TIMESTAMP",
+	BLOCKHEIGHT:           "BLOCKHEIGHT",
+	DIFFICULTY_DEPRECATED: "DIFFICULTY_DEPRECATED",
+	GASLIMIT:              "GASLIMIT",
+	CHAINID:               "CHAINID",
+	SELFBALANCE:           "SELFBALANCE",
+
+	// 0x50 range - 'storage' and execution
+	POP:      "POP",
+	MLOAD:    "MLOAD",
+	MSTORE:   "MSTORE",
+	MSTORE8:  "MSTORE8",
+	SLOAD:    "SLOAD",
+	SSTORE:   "SSTORE",
+	JUMP:     "JUMP",
+	JUMPI:    "JUMPI",
+	PC:       "PC",
+	MSIZE:    "MSIZE",
+	GAS:      "GAS",
+	JUMPDEST: "JUMPDEST",
+
+	// 0x60 range - push
+	PUSH1:  "PUSH1",
+	PUSH2:  "PUSH2",
+	PUSH3:  "PUSH3",
+	PUSH4:  "PUSH4",
+	PUSH5:  "PUSH5",
+	PUSH6:  "PUSH6",
+	PUSH7:  "PUSH7",
+	PUSH8:  "PUSH8",
+	PUSH9:  "PUSH9",
+	PUSH10: "PUSH10",
+	PUSH11: "PUSH11",
+	PUSH12: "PUSH12",
+	PUSH13: "PUSH13",
+	PUSH14: "PUSH14",
+	PUSH15: "PUSH15",
+	PUSH16: "PUSH16",
+	PUSH17: "PUSH17",
+	PUSH18: "PUSH18",
+	PUSH19: "PUSH19",
+	PUSH20: "PUSH20",
+	PUSH21: "PUSH21",
+	PUSH22: "PUSH22",
+	PUSH23: "PUSH23",
+	PUSH24: "PUSH24",
+	PUSH25: "PUSH25",
+	PUSH26: "PUSH26",
+	PUSH27: "PUSH27",
+	PUSH28: "PUSH28",
+	PUSH29: "PUSH29",
+	PUSH30: "PUSH30",
+	PUSH31: "PUSH31",
+	PUSH32: "PUSH32",
+
+	DUP1:  "DUP1",
+	DUP2:  "DUP2",
+	DUP3:  "DUP3",
+	DUP4:  "DUP4",
+	DUP5:  "DUP5",
+	DUP6:  "DUP6",
+	DUP7:  "DUP7",
+	DUP8:  "DUP8",
+	DUP9:  "DUP9",
+	DUP10: "DUP10",
+	DUP11: "DUP11",
+	DUP12: "DUP12",
+	DUP13: "DUP13",
+	DUP14: "DUP14",
+	DUP15: "DUP15",
+	DUP16: "DUP16",
+
+	SWAP1:  "SWAP1",
+	SWAP2:  "SWAP2",
+	SWAP3:  "SWAP3",
+	SWAP4:  "SWAP4",
+	SWAP5:  "SWAP5",
+	SWAP6:  "SWAP6",
+	SWAP7:  "SWAP7",
+	SWAP8:  "SWAP8",
+	SWAP9:  "SWAP9",
+	SWAP10: "SWAP10",
+	SWAP11: "SWAP11",
+	SWAP12: "SWAP12",
+	SWAP13: "SWAP13",
+	SWAP14: "SWAP14",
+	SWAP15: "SWAP15",
+	SWAP16: "SWAP16",
+	LOG0:   "LOG0",
+	LOG1:   "LOG1",
+	LOG2:   "LOG2",
+	LOG3:   "LOG3",
+	LOG4:   "LOG4",
+
+	// 0xf0 range
+	CREATE:       "CREATE",
+	CALL:         "CALL",
+	RETURN:       "RETURN",
+	CALLCODE:     "CALLCODE",
+	DELEGATECALL: "DELEGATECALL",
+	STATICCALL:   "STATICCALL",
+	// 0x70 range - other
+	CREATE2:      "CREATE2",
+	REVERT:       "REVERT",
+	INVALID:      "INVALID",
+	SELFDESTRUCT: "SELFDESTRUCT",
+
+	EIP1014_CREATE2: "CREATE2",
+}
+
+func GetOpCode(b byte) (OpCode, bool) {
+	op := OpCode(b)
+	_, isOpcode := opCodeNames[op]
+	return op, isOpcode
+
+}
+
+func (o OpCode) String() string {
+	return o.Name()
+}
+
+func (o OpCode) Name() string {
+	str := opCodeNames[o]
+	if len(str) == 0 {
+		return fmt.Sprintf("Non-opcode 0x%x", int(o))
+	}
+
+	return str
+}
+
+// If OpCode is a Push<N> returns the number of bytes pushed (between 1 and 32 inclusive)
+func (o OpCode) Pushes() int {
+	if o >= PUSH1 && o <= PUSH32 {
+		return int(o - PUSH1 + 1)
+	}
+	return 0
+}
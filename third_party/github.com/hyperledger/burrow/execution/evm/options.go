@@ -0,0 +1,115 @@
+package evm
+
+import (
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/errors"
+	. "github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm/asm"
+)
+
+func MemoryProvider(memoryProvider func(errors.Sink) Memory) func(*VM) {
+	return func(vm *VM) {
+		vm.memoryProvider = memoryProvider
+	}
+}
+
+func DebugOpcodes(vm *VM) {
+	vm.debugOpcodes = true
+}
+
+func DumpTokens(vm *VM) {
+	vm.dumpTokens = true
+}
+
+// Step is the state of the EVM before it executes an opcode.
+type Step struct {
+	PC      uint64
+	Op      OpCode
+	Gas     uint64
+	Depth   uint64
+	Address crypto.Address
+	// Stack holds the words of the stack, the top of the stack last
+	Stack  []binary.Word256
+	Memory Memory
+	State  Interface
+}
+
+// StepTracer sets a function which is called with the state of the EVM before
+// each opcode is executed.
+func StepTracer(tracer func(*Step)) func(*VM) {
+	return func(vm *VM) {
+		vm.stepTracer = tracer
+	}
+}
+
+// CallChecker sets a function which is called with the address of every
+// contract a contract calls before the call is made. When it returns an error
+// the calling contract fails with it.
+func CallChecker(checker func(address crypto.Address) error) func(*VM) {
+	return func(vm *VM) {
+		vm.callChecker = checker
+	}
+}
+
+// CreateChecker sets a function which is called with the address of every
+// contract which creates another contract before it is created. When it
+// returns an error the creating contract fails with it.
+func CreateChecker(checker func(creator crypto.Address) error) func(*VM) {
+	return func(vm *VM) {
+		vm.createChecker = checker
+	}
+}
+
+// ContractNonces derives the addresses of the contracts created by CREATE from
+// the address and the nonce of the creating contract with newContractAddress,
+// as Ethereum does. The nonce of a contract is the sequence of its account,
+// which starts at 1 and is incremented by every contract it creates, as
+// specified by EIP-161.
+func ContractNonces(newContractAddress func(creator crypto.Address, nonce uint64) crypto.Address) func(*VM) {
+	return func(vm *VM) {
+		vm.newContractAddress = newContractAddress
+	}
+}
+
+func StackOptions(callStackMaxDepth uint64, dataStackInitialCapacity uint64, dataStackMaxDepth uint64) func(*VM) {
+	return func(vm *VM) {
+		vm.params.CallStackMaxDepth = callStackMaxDepth
+		vm.params.DataStackInitialCapacity = dataStackInitialCapacity
+		vm.params.DataStackMaxDepth = dataStackMaxDepth
+	}
+}
+
+// EIP1014 moves CREATE2 to opcode 0xf5 and derives the address of the created
+// contract from the init code as specified by EIP-1014. Opcode 0xfb becomes
+// invalid.
+func EIP1014(vm *VM) {
+	vm.eip1014 = true
+}
+
+// EIP1344 enables the CHAINID opcode, which returns the given chain ID.
+func EIP1344(chainID uint64) func(*VM) {
+	return func(vm *VM) {
+		vm.eip1344 = true
+		vm.chainID = chainID
+	}
+}
+
+// EIP1884 enables the SELFBALANCE opcode.
+func EIP1884(vm *VM) {
+	vm.eip1884 = true
+}
+
+// isEnabled returns whether an opcode which depends on an EIP is enabled.
+func (vm *VM) isEnabled(op OpCode) bool {
+	switch op {
+	case CREATE2:
+		return !vm.eip1014
+	case EIP1014_CREATE2:
+		return vm.eip1014
+	case CHAINID:
+		return vm.eip1344
+	case SELFBALANCE:
+		return vm.eip1884
+	}
+	return true
+}
@@ -144,6 +144,13 @@ func (st *Stack) PopBigInt() *big.Int {
 	return new(big.Int).SetBytes(d[:])
 }
 
+// Words returns a copy of the words of the stack, the top of the stack last
+func (st *Stack) Words() []Word256 {
+	words := make([]Word256, st.ptr)
+	copy(words, st.slice[:st.ptr])
+	return words
+}
+
 func (st *Stack) Len() int {
 	return st.ptr
 }
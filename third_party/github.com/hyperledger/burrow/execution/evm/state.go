@@ -38,6 +38,7 @@ type Writer interface {
 	CreateAccount(address crypto.Address)
 	InitCode(address crypto.Address, code []byte)
 	RemoveAccount(address crypto.Address)
+	IncSequence(address crypto.Address)
 	SetStorage(address crypto.Address, key, value binary.Word256)
 	AddToBalance(address crypto.Address, amount uint64)
 	SubtractFromBalance(address crypto.Address, amount uint64)
@@ -196,6 +197,17 @@ func (st *State) RemoveAccount(address crypto.Address) {
 	st.removeAccount(address)
 }
 
+// IncSequence increments the sequence of an account, which is the nonce of a
+// contract when the VM uses contract nonces.
+func (st *State) IncSequence(address crypto.Address) {
+	acc := st.mustAccount(address)
+	if acc == nil {
+		return
+	}
+	acc.Sequence++
+	st.updateAccount(acc)
+}
+
 func (st *State) SetStorage(address crypto.Address, key, value binary.Word256) {
 	err := st.cache.SetStorage(address, key, value)
 	if err != nil {
@@ -29,11 +29,11 @@ import (
 	"github.com/hyperledger/burrow/crypto"
 	"github.com/hyperledger/burrow/crypto/sha3"
 	"github.com/hyperledger/burrow/execution/errors"
-	. "github.com/hyperledger/burrow/execution/evm/asm"
 	"github.com/hyperledger/burrow/execution/exec"
 	"github.com/hyperledger/burrow/logging"
 	"github.com/hyperledger/burrow/permission"
 	"github.com/hyperledger/burrow/txs"
+	. "github.com/hyperledger/fabric-chaincode-evm/third_party/github.com/hyperledger/burrow/execution/evm/asm"
 )
 
 const (
@@ -42,6 +42,10 @@ const (
 	uint64Length                = 8
 )
 
+// CallTypeCreate is the call type of the init code of a contract created by
+// the CREATE and CREATE2 opcodes
+const CallTypeCreate = exec.CallType(0x05)
+
 type Params struct {
 	BlockHeight              uint64
 	BlockTime                int64
@@ -61,6 +65,15 @@ type VM struct {
 	debugOpcodes   bool
 	dumpTokens     bool
 	sequence       uint64
+	eip1014        bool
+	eip1344        bool
+	eip1884        bool
+	chainID        uint64
+	stepTracer     func(*Step)
+	callChecker    func(crypto.Address) error
+	createChecker  func(crypto.Address) error
+	// newContractAddress is set when contracts have nonces
+	newContractAddress func(creator crypto.Address, nonce uint64) crypto.Address
 }
 
 // Create a new EVM instance. Nonce is required to be globally unique (nearly almost surely) to avoid duplicate
@@ -247,9 +260,27 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 
 		var op = codeGetOp(code, pc)
 		vm.Debugf("(pc) %-3d (op) %-14s (st) %-4d (gas) %d", pc, op.String(), stack.Len(), *gas)
+		if vm.stepTracer != nil {
+			vm.stepTracer(&Step{
+				PC:      uint64(pc),
+				Op:      op,
+				Gas:     *gas,
+				Depth:   vm.stackDepth,
+				Address: callee,
+				Stack:   stack.Words(),
+				Memory:  memory,
+				State:   callState,
+			})
+		}
 		// Use BaseOp gas.
 		useGasNegative(gas, GasBaseOp, callState)
 
+		if !vm.isEnabled(op) {
+			vm.Debugf("(pc) %-3v Unknown opcode %v\n", pc, op)
+			callState.PushError(errors.Errorf("unknown opcode %v", op))
+			return nil
+		}
+
 		switch op {
 
 		case ADD: // 0x01
@@ -641,13 +672,14 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 		case BLOCKHASH: // 0x40
 			blockNumber := stack.PopU64()
 
+			// As in Ethereum, the hash of a block outside of the allowed range is zero
 			if blockNumber >= vm.params.BlockHeight {
 				vm.Debugf(" => attempted to get block hash of a non-existent block: %v", blockNumber)
-				callState.PushError(errors.ErrorCodeInvalidBlockNumber)
+				stack.Push(Zero256)
 			} else if vm.params.BlockHeight-blockNumber > MaximumAllowedBlockLookBack {
 				vm.Debugf(" => attempted to get block hash of a block %d outside of the allowed range "+
 					"(must be within %d blocks)", blockNumber, MaximumAllowedBlockLookBack)
-				callState.PushError(errors.ErrorCodeBlockNumberOutOfRange)
+				stack.Push(Zero256)
 			} else {
 				blockHash, err := callState.GetBlockHash(blockNumber)
 				if err != nil {
@@ -677,6 +709,15 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 			stack.PushU64(vm.params.GasLimit)
 			vm.Debugf(" => %v\n", vm.params.GasLimit)
 
+		case CHAINID: // 0x46
+			stack.PushU64(vm.chainID)
+			vm.Debugf(" => %v\n", vm.chainID)
+
+		case SELFBALANCE: // 0x47
+			balance := callState.GetBalance(callee)
+			stack.PushU64(balance)
+			vm.Debugf(" => %v (%X)\n", balance, callee)
+
 		case POP: // 0x50
 			popped := stack.Pop()
 			vm.Debugf(" => 0x%X\n", popped)
@@ -777,7 +818,7 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 			}))
 			vm.Debugf(" => T:%X D:%X\n", topics, data)
 
-		case CREATE, CREATE2: // 0xF0, 0xFB
+		case CREATE, CREATE2, EIP1014_CREATE2: // 0xF0, 0xFB, 0xF5
 			returnData = nil
 			contractValue := stack.PopU64()
 			offset, size := stack.PopBigInt(), stack.PopBigInt()
@@ -787,7 +828,9 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 			useGasNegative(gas, GasCreateAccount, callState)
 
 			var newAccount crypto.Address
-			if op == CREATE {
+			if op == CREATE && vm.newContractAddress != nil {
+				newAccount = vm.newContractAddress(callee, callState.GetSequence(callee))
+			} else if op == CREATE {
 				vm.sequence++
 				nonce := make([]byte, txs.HashLength+uint64Length)
 				copy(nonce, vm.nonce)
@@ -796,6 +839,9 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 			} else if op == CREATE2 {
 				salt := stack.Pop()
 				newAccount = crypto.NewContractAddress2(callee, salt, callState.GetCode(callee))
+			} else if op == EIP1014_CREATE2 {
+				salt := stack.Pop()
+				newAccount = crypto.NewContractAddress2(callee, salt, input)
 			}
 
 			// Check the CreateContract permission for this account
@@ -804,13 +850,30 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 				continue
 			}
 
+			if vm.createChecker != nil {
+				if err := vm.createChecker(callee); err != nil {
+					callState.PushError(errors.ErrorCodef(errors.ErrorCodePermissionDenied, "%s", err))
+					continue
+				}
+			}
+
+			if vm.newContractAddress != nil {
+				callState.IncSequence(callee)
+			}
+
 			// Establish a frame in which the putative account exists
 			childCallState := callState.NewCache()
 			create(childCallState, newAccount)
+			if vm.newContractAddress != nil {
+				childCallState.IncSequence(newAccount)
+			}
 
 			// Run the input to get the contract code.
 			// NOTE: no need to copy 'input' as per Call contract.
-			ret, callErr := vm.Call(childCallState, eventSink, callee, newAccount, input, input, contractValue, gas)
+			ret, callErr := vm.call(childCallState, eventSink, callee, newAccount, input, input, contractValue, gas, CallTypeCreate)
+			if callErr == nil {
+				callErr = childCallState.Error()
+			}
 			if callErr != nil {
 				stack.Push(Zero256)
 				// Note we both set the return buffer and return the result normally
@@ -846,6 +909,13 @@ func (vm *VM) execute(callState Interface, eventSink EventSink, caller, callee c
 			retSize := stack.Pop64()
 			vm.Debugf(" => %v\n", address)
 
+			if vm.callChecker != nil {
+				if err := vm.callChecker(address); err != nil {
+					callState.PushError(errors.ErrorCodef(errors.ErrorCodePermissionDenied, "%s", err))
+					continue
+				}
+			}
+
 			// Get the arguments from the memory
 			args := memory.Read(inOffset, inSize)
 